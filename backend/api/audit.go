@@ -0,0 +1,170 @@
+package api
+
+import (
+	"brew-manager/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const auditBufferSize = 256
+
+type AuditEntry struct {
+	Timestamp  string `json:"timestamp"`
+	RequestID  string `json:"request_id"`
+	RemoteAddr string `json:"remote_addr"`
+	Operation  string `json:"operation"`
+	Target     string `json:"target,omitempty"`
+	Outcome    string `json:"outcome"`
+	Status     int    `json:"status"`
+}
+
+type AuditLogger struct {
+	file    *os.File
+	entries chan AuditEntry
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewAuditLogger(path string) (*AuditLogger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %q: %w", path, err)
+	}
+
+	a := &AuditLogger{
+		file:    f,
+		entries: make(chan AuditEntry, auditBufferSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a, nil
+}
+
+func (a *AuditLogger) Log(ctx context.Context, entry AuditEntry) {
+	if a == nil {
+		return
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		logFromCtx(ctx).Warnf("Audit log closed, dropping entry for %s %s", entry.Operation, entry.Target)
+		return
+	}
+
+	select {
+	case a.entries <- entry:
+	default:
+		logFromCtx(ctx).Warnf("Audit log buffer full, dropping entry for %s %s", entry.Operation, entry.Target)
+	}
+}
+
+// Close stops accepting entries, waits for the queued ones to be written and
+// closes the file. Entries logged afterwards, e.g. by handlers still running
+// after a timed-out shutdown, are dropped.
+func (a *AuditLogger) Close() error {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.entries)
+	a.mu.Unlock()
+
+	<-a.done
+	return a.file.Close()
+}
+
+func (a *AuditLogger) run() {
+	defer close(a.done)
+
+	for entry := range a.entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			logging.Errorf("Failed to encode audit entry: %v", err)
+			continue
+		}
+		if _, err := a.file.Write(append(line, '\n')); err != nil {
+			logging.Errorf("Failed to write audit entry: %v", err)
+		}
+	}
+}
+
+func AuditMiddleware(next http.Handler, a *AuditLogger) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		wrapped := wrapResponseWriter(w)
+		next.ServeHTTP(wrapped, r)
+
+		operation, target := auditOperation(r)
+		outcome := "success"
+		if wrapped.status >= 400 {
+			outcome = "failure"
+		}
+
+		a.Log(r.Context(), AuditEntry{
+			Timestamp:  time.Now().UTC().Format(time.RFC3339),
+			RequestID:  RequestIDFromContext(r.Context()),
+			RemoteAddr: clientIP(r),
+			Operation:  operation,
+			Target:     target,
+			Outcome:    outcome,
+			Status:     wrapped.status,
+		})
+	})
+}
+
+func AuditMiddlewareFunc(a *AuditLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return AuditMiddleware(next, a)
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func auditOperation(r *http.Request) (string, string) {
+	operation, target := routeOperation(r)
+	if action := r.URL.Query().Get("action"); action != "" {
+		operation += ":" + action
+	}
+	return operation, target
+}
+
+func routeOperation(r *http.Request) (string, string) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	target := r.URL.Query().Get("name")
+
+	parts := strings.Split(path, "/")
+	if len(parts) == 3 && parts[0] == "packages" {
+		return parts[0] + "/" + parts[2], parts[1]
+	}
+	return path, target
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readAuditEntries(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("audit line %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditMiddlewareEntryFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := NewAuditLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := RequestIDMiddleware(AuditMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), audit))
+	req := httptest.NewRequest(http.MethodPost, "/api/packages/install?name=wget", nil)
+	req.Header.Set(requestIDHeader, "req-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/packages", nil))
+
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	entries := readAuditEntries(t, path)
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1 for the single mutating request", len(entries))
+	}
+	entry := entries[0]
+	if entry.RequestID != "req-123" || entry.Operation != "packages/install" || entry.Target != "wget" ||
+		entry.Outcome != "success" || entry.Status != http.StatusOK || entry.RemoteAddr == "" {
+		t.Errorf("unexpected audit entry %+v", entry)
+	}
+	if _, err := time.Parse(time.RFC3339, entry.Timestamp); err != nil {
+		t.Errorf("timestamp %q is not RFC3339: %v", entry.Timestamp, err)
+	}
+}
+
+func TestAuditLoggerDoesNotBlockWhenFull(t *testing.T) {
+	audit := &AuditLogger{entries: make(chan AuditEntry, 1)}
+
+	done := make(chan struct{})
+	go func() {
+		audit.Log(context.Background(), AuditEntry{Operation: "packages/install"})
+		audit.Log(context.Background(), AuditEntry{Operation: "packages/uninstall"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Log blocked on a full buffer")
+	}
+	if len(audit.entries) != 1 {
+		t.Errorf("buffer holds %d entries, want 1", len(audit.entries))
+	}
+}
+
+func TestAuditLoggerCloseDrainsQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := NewAuditLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const queued = 50
+	for i := 0; i < queued; i++ {
+		audit.Log(context.Background(), AuditEntry{Operation: "packages/install", Target: "wget"})
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	audit.Log(context.Background(), AuditEntry{Operation: "packages/install", Target: "late"})
+	if err := audit.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	if got := len(readAuditEntries(t, path)); got != queued {
+		t.Fatalf("audit log has %d entries after Close, want %d", got, queued)
+	}
+}
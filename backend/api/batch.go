@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+type BatchItemResult struct {
+	Target     string `json:"target"`
+	Status     string `json:"status"`
+	Code       string `json:"code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	httpStatus int
+}
+
+type BatchResult struct {
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Skipped   int               `json:"skipped,omitempty"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+func NewBatchResult(capacity int) *BatchResult {
+	return &BatchResult{
+		Results: make([]BatchItemResult, 0, capacity),
+	}
+}
+
+func (b *BatchResult) Add(ctx context.Context, target string, err error) {
+	if err == nil {
+		b.AddSuccess(target, "success")
+		return
+	}
+
+	status, code, message, _ := classifyBrewError(ctx, err)
+	b.AddFailure(target, status, code, message)
+}
+
+func (b *BatchResult) AddSuccess(target, status string) {
+	b.Total++
+	b.Succeeded++
+	b.Results = append(b.Results, BatchItemResult{
+		Target:     target,
+		Status:     status,
+		httpStatus: http.StatusOK,
+	})
+}
+
+func (b *BatchResult) AddFailure(target string, httpStatus int, code, message string) {
+	b.Total++
+	b.Failed++
+	b.Results = append(b.Results, BatchItemResult{
+		Target:     target,
+		Status:     "failed",
+		Code:       code,
+		Error:      message,
+		httpStatus: httpStatus,
+	})
+}
+
+func (b *BatchResult) AddSkipped(target, code, reason string) {
+	b.Total++
+	b.Skipped++
+	b.Results = append(b.Results, BatchItemResult{
+		Target:     target,
+		Status:     "skipped",
+		Code:       code,
+		Error:      reason,
+		httpStatus: http.StatusConflict,
+	})
+}
+
+func (b *BatchResult) HTTPStatus() int {
+	switch {
+	case b.Failed == 0 && b.Skipped == 0:
+		return http.StatusOK
+	case b.Succeeded > 0:
+		return http.StatusMultiStatus
+	case b.Failed == 0:
+		return http.StatusConflict
+	}
+
+	for _, result := range b.Results {
+		if result.httpStatus >= 500 {
+			return http.StatusInternalServerError
+		}
+	}
+	return http.StatusBadRequest
+}
+
+func writeBatchResult(w http.ResponseWriter, r *http.Request, b *BatchResult) {
+	writeJSON(w, r, b.HTTPStatus(), b)
+}
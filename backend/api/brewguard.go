@@ -0,0 +1,97 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// brewIndependentRoutes never invoke brew, so they stay reachable while it is
+// missing; operators need them to inspect or stop the server.
+var brewIndependentRoutes = map[string]bool{
+	"/api/system/config":   true,
+	"/api/system/prefixes": true,
+	"/api/system/shutdown": true,
+	"/api/stats":           true,
+	"/api/error-codes":     true,
+	"/api/operations":      true,
+	"/api/events":          true,
+	"/api/packages/labels": true,
+}
+
+type BrewGuard struct {
+	brew      *brew.ServiceManager
+	interval  time.Duration
+	available atomic.Bool
+}
+
+func NewBrewGuard(b *brew.ServiceManager, interval time.Duration) *BrewGuard {
+	g := &BrewGuard{
+		brew:     b,
+		interval: interval,
+	}
+	g.check()
+	return g
+}
+
+func (g *BrewGuard) Available() bool {
+	return g.available.Load()
+}
+
+func (g *BrewGuard) Start(ctx context.Context) {
+	if g.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.check()
+			}
+		}
+	}()
+}
+
+func (g *BrewGuard) check() {
+	available := g.brew.IsAvailable()
+	previous := g.available.Swap(available)
+
+	switch {
+	case !available && previous:
+		log.Printf("WARN: Homebrew is no longer available; API requests will return 503")
+	case !available:
+		log.Printf("WARN: Homebrew not found in PATH; API requests will return 503 until it is installed")
+	case available && !previous:
+		log.Printf("INFO: Homebrew detected; API requests enabled")
+	}
+}
+
+func BrewGuardMiddleware(next http.Handler, g *BrewGuard) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") && !brewIndependentRoutes[r.URL.Path] &&
+			r.Method != http.MethodOptions && !g.Available() {
+			writeError(w, r, http.StatusServiceUnavailable, ErrCodeBrewNotFound,
+				"Homebrew is not installed or not in PATH. Please install Homebrew from https://brew.sh",
+			)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func BrewGuardMiddlewareFunc(g *BrewGuard) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return BrewGuardMiddleware(next, g)
+	}
+}
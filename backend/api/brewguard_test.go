@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBrewGuardExemptsBrewIndependentRoutes(t *testing.T) {
+	guard := &BrewGuard{}
+	handler := BrewGuardMiddleware(okHandler, guard)
+
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodGet, "/api/system/config", http.StatusOK},
+		{http.MethodGet, "/api/stats", http.StatusOK},
+		{http.MethodGet, "/api/error-codes", http.StatusOK},
+		{http.MethodPost, "/api/system/shutdown", http.StatusOK},
+		{http.MethodGet, "/api/packages/labels", http.StatusOK},
+		{http.MethodGet, "/api/packages", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/packages/install?name=wget", http.StatusServiceUnavailable},
+		{http.MethodGet, "/api/system/config/extra", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
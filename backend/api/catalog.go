@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	defaultCatalogPageSize = 100
+	maxCatalogPageSize     = 1000
+)
+
+type CatalogPage struct {
+	Names      []string `json:"names"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	Total      int      `json:"total"`
+}
+
+func (h *Handler) ListCatalogFormulae(w http.ResponseWriter, r *http.Request) {
+	h.listCatalog(w, r, h.brew.AllFormulae)
+}
+
+func (h *Handler) ListCatalogCasks(w http.ResponseWriter, r *http.Request) {
+	h.listCatalog(w, r, h.brew.AllCasks)
+}
+
+func (h *Handler) listCatalog(w http.ResponseWriter, r *http.Request, fetch func(context.Context) ([]string, error)) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	names, err := fetch(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	if !query.Has("cursor") && !query.Has("limit") {
+		writeJSONWithETag(w, r, http.StatusOK, nonNil(names))
+		return
+	}
+
+	offset, err := decodeCatalogCursor(query.Get("cursor"))
+	if err != nil {
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Query parameter 'cursor' is invalid",
+			map[string]string{"field": "cursor"},
+		)
+		return
+	}
+
+	limit := defaultCatalogPageSize
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxCatalogPageSize {
+			writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+				fmt.Sprintf("Query parameter 'limit' must be an integer between 1 and %d", maxCatalogPageSize),
+				map[string]string{"field": "limit"},
+			)
+			return
+		}
+	}
+
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+
+	start := min(offset, len(sorted))
+	end := min(start+limit, len(sorted))
+	page := CatalogPage{
+		Names: append([]string{}, sorted[start:end]...),
+		Total: len(sorted),
+	}
+	if end < len(sorted) {
+		page.NextCursor = encodeCatalogCursor(end)
+	}
+
+	writeJSONWithETag(w, r, http.StatusOK, page)
+}
+
+func encodeCatalogCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCatalogCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor offset %q", raw)
+	}
+	return offset, nil
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+)
+
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+	limit    int
+}
+
+func NewConcurrencyLimiter(limit int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		inFlight: make(map[string]int),
+		limit:    limit,
+	}
+}
+
+func (l *ConcurrencyLimiter) acquire(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[key] >= l.limit {
+		return false
+	}
+	l.inFlight[key]++
+	return true
+}
+
+func (l *ConcurrencyLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight[key]--
+	if l.inFlight[key] <= 0 {
+		delete(l.inFlight, key)
+	}
+}
+
+func ConcurrencyLimitMiddleware(next http.Handler, l *ConcurrencyLimiter) http.Handler {
+	if l == nil || l.limit <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingOperation(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := clientIP(r)
+		if !l.acquire(ip) {
+			w.Header().Set("Retry-After", "5")
+			writeError(w, r, http.StatusTooManyRequests, ErrCodeRateLimited,
+				"Too many concurrent operations from this client. Wait for one to finish and try again.",
+			)
+			return
+		}
+		defer l.release(ip)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ConcurrencyLimitMiddlewareFunc(l *ConcurrencyLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return ConcurrencyLimitMiddleware(next, l)
+	}
+}
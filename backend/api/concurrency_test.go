@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConcurrencyLimitOnlyCountsMutations(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	// Hold the single slot for this client, as an in-flight install would.
+	if !limiter.acquire("192.0.2.1") {
+		t.Fatal("could not acquire the only slot")
+	}
+
+	handler := ConcurrencyLimitMiddleware(okHandler, limiter)
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodPost, "/api/packages/install?name=wget", http.StatusTooManyRequests},
+		{http.MethodPost, "/api/packages/wget/upgrade", http.StatusTooManyRequests},
+		{http.MethodPost, "/api/import?apply=true", http.StatusTooManyRequests},
+		{http.MethodPost, "/api/brew/exec", http.StatusOK},
+		{http.MethodPost, "/api/import", http.StatusOK},
+		{http.MethodPost, "/api/packages/install-preview?name=wget", http.StatusOK},
+		{http.MethodGet, "/api/packages", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		req.RemoteAddr = "192.0.2.1:5000"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
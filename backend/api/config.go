@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+)
+
+type ServerSettings struct {
+	Port                 string   `json:"port"`
+	CORSOrigins          []string `json:"cors_origins"`
+	CORSMethods          []string `json:"cors_methods"`
+	CORSHeaders          []string `json:"cors_headers"`
+	CORSAllowCredentials bool     `json:"cors_allow_credentials"`
+	TLS                  bool     `json:"tls"`
+	ReadOnly             bool     `json:"readonly"`
+	AuditLog             bool     `json:"audit_log"`
+	Webhook              bool     `json:"webhook"`
+	LogLevel             string   `json:"log_level"`
+	LogRedact            bool     `json:"log_redact"`
+	OutdatedPollInterval string   `json:"outdated_poll_interval,omitempty"`
+	CleanupInterval      string   `json:"cleanup_interval,omitempty"`
+	ShutdownTimeout      string   `json:"shutdown_timeout"`
+	MaxConcurrentPerIP   int      `json:"max_concurrent_per_ip"`
+	MaxHeaderBytes       int      `json:"max_header_bytes"`
+	MaxConnections       int      `json:"max_connections,omitempty"`
+	RemoteShutdown       bool     `json:"remote_shutdown"`
+	ReadTimeout          string   `json:"read_timeout"`
+	WriteTimeout         string   `json:"write_timeout"`
+	IdleTimeout          string   `json:"idle_timeout"`
+	TrustedProxies       []string `json:"trusted_proxies"`
+	SSEKeepAlive         string   `json:"sse_keepalive_interval"`
+	MaxStreams           int      `json:"max_streams"`
+}
+
+type BrewSettings struct {
+	BrewPath            string            `json:"brew_path"`
+	Prefixes            map[string]string `json:"prefixes"`
+	CommandTimeout      string            `json:"command_timeout"`
+	CommandTimeouts     map[string]string `json:"command_timeouts"`
+	HTTPTimeout         string            `json:"http_timeout"`
+	DisableCheatSheet   bool              `json:"disable_cheatsheet"`
+	ProxyURL            string            `json:"proxy_url,omitempty"`
+	MaxConcurrentReads  int               `json:"max_concurrent_reads"`
+	CheatSheetUserAgent string            `json:"cheatsheet_user_agent"`
+	CheatSheetAccept    string            `json:"cheatsheet_accept,omitempty"`
+	MaxOutputBytes      int64             `json:"max_output_bytes"`
+}
+
+type ConfigResponse struct {
+	Server         ServerSettings    `json:"server"`
+	Brew           BrewSettings      `json:"brew"`
+	RequestTimeout string            `json:"request_timeout"`
+	RouteTimeouts  map[string]string `json:"route_timeouts"`
+}
+
+func (h *Handler) SetServerSettings(settings ServerSettings) {
+	h.settings = settings
+}
+
+func (h *Handler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	cfg := h.brew.Config()
+
+	timeouts := make(map[string]string, len(cfg.CommandTimeouts))
+	for category, timeout := range cfg.CommandTimeouts {
+		timeouts[string(category)] = timeout.String()
+	}
+
+	writeJSON(w, r, http.StatusOK, ConfigResponse{
+		Server: h.settings,
+		Brew: BrewSettings{
+			BrewPath:            cfg.BrewPath,
+			Prefixes:            cfg.Prefixes,
+			CommandTimeout:      cfg.CommandTimeout.String(),
+			CommandTimeouts:     timeouts,
+			HTTPTimeout:         cfg.HTTPTimeout.String(),
+			DisableCheatSheet:   cfg.DisableCheatSheet,
+			ProxyURL:            redactURL(cfg.ProxyURL),
+			MaxConcurrentReads:  cfg.MaxConcurrentReads,
+			CheatSheetUserAgent: cfg.CheatSheetUserAgent,
+			CheatSheetAccept:    cfg.CheatSheetAccept,
+			MaxOutputBytes:      cfg.MaxOutputBytes,
+		},
+		RequestTimeout: h.requestTimeout.String(),
+		RouteTimeouts:  h.routeTimeoutSettings(),
+	})
+}
+
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+	}
+	return u.String()
+}
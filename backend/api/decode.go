@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	maxJSONBodyBytes = 64 * 1024
+	// An export document lists every installed package with its versions,
+	// taps, pins and services, which easily outgrows the default limit.
+	maxImportBodyBytes = 4 * 1024 * 1024
+)
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return decodeJSONBodyLimit(w, r, dst, maxJSONBodyBytes)
+}
+
+func decodeJSONBodyLimit(w http.ResponseWriter, r *http.Request, dst interface{}, limit int64) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil {
+		if _, err := dec.Token(); err == io.EOF {
+			return true
+		}
+		err = errors.New("trailing data after JSON object")
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Request body must not be empty",
+			map[string]string{"reason": "empty_body"})
+	case errors.As(err, &maxBytesErr):
+		writeErrorWithDetails(w, r, http.StatusRequestEntityTooLarge, ErrCodeValidation,
+			fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit),
+			map[string]string{"reason": "body_too_large"})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Request body contains malformed JSON",
+			map[string]string{"reason": "malformed_json"})
+	case errors.As(err, &typeErr):
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			fmt.Sprintf("Request body field %q has the wrong type", typeErr.Field),
+			map[string]string{"reason": "invalid_type", "field": typeErr.Field})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			fmt.Sprintf("Request body contains unknown field %q", field),
+			map[string]string{"reason": "unknown_field", "field": field})
+	default:
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Request body must be a single valid JSON object",
+			map[string]string{"reason": "malformed_json"})
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func largeJSONBody(t *testing.T, size int) []byte {
+	t.Helper()
+
+	var names []string
+	for n := 0; n < size; n += 16 {
+		names = append(names, fmt.Sprintf("formula-%06d", len(names)))
+	}
+	body, err := json.Marshal(map[string][]string{"names": names})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestDecodeJSONBodyLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		limit      int64
+		wantOK     bool
+		wantStatus int
+	}{
+		{"small body default limit", 1024, maxJSONBodyBytes, true, http.StatusOK},
+		{"large body default limit", 256 * 1024, maxJSONBodyBytes, false, http.StatusRequestEntityTooLarge},
+		{"large body import limit", 256 * 1024, maxImportBodyBytes, true, http.StatusOK},
+		{"oversized import", maxImportBodyBytes + 1024, maxImportBodyBytes, false, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/import", bytes.NewReader(largeJSONBody(t, tt.size)))
+			rec := httptest.NewRecorder()
+
+			var dst struct {
+				Names []string `json:"names"`
+			}
+			if ok := decodeJSONBodyLimit(rec, req, &dst, tt.limit); ok != tt.wantOK {
+				t.Fatalf("decodeJSONBodyLimit = %v, want %v (body %s)", ok, tt.wantOK, rec.Body.String())
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
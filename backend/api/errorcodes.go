@@ -0,0 +1,35 @@
+package api
+
+import "net/http"
+
+type ErrorCodeInfo struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+var errorCodes = []ErrorCodeInfo{
+	{ErrCodeValidation, http.StatusBadRequest, "The request has a missing or invalid parameter or body."},
+	{ErrCodeNotFound, http.StatusNotFound, "The package, version, or resource does not exist or is not installed."},
+	{ErrCodeMethodNotAllow, http.StatusMethodNotAllowed, "The HTTP method is not supported by this endpoint."},
+	{ErrCodeTimeout, http.StatusGatewayTimeout, "The Homebrew command took too long to complete."},
+	{ErrCodeInternal, http.StatusInternalServerError, "An unexpected server or Homebrew command failure occurred."},
+	{ErrCodeUnavailable, http.StatusServiceUnavailable, "The server is not ready or Homebrew is unreachable."},
+	{ErrCodeBrewNotFound, http.StatusServiceUnavailable, "The Homebrew binary could not be found on the server."},
+	{ErrCodeReadOnly, http.StatusForbidden, "The server runs in read-only mode and rejects mutating operations."},
+	{ErrCodeRateLimited, http.StatusTooManyRequests, "Too many concurrent operations from this client; retry later."},
+	{ErrCodeAmbiguous, http.StatusConflict, "The name matches both a formula and a cask; pass type=formula or type=cask."},
+	{ErrCodeConflict, http.StatusConflict, "The operation conflicts with the current state, e.g. the package is already installed."},
+	{ErrCodeForbidden, http.StatusForbidden, "The requested brew command or argument is not on the read-only allowlist."},
+	{ErrCodeBrewLocked, http.StatusConflict, "Another Homebrew process holds the lock; the request is safe to retry shortly."},
+	{ErrCodeOutputTooLarge, http.StatusBadGateway, "The Homebrew command produced more output than MAX_OUTPUT_BYTES allows."},
+	{ErrCodeUnauthorized, http.StatusUnauthorized, "The endpoint requires a valid bearer token in the Authorization header."},
+}
+
+func (h *Handler) ListErrorCodes(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSONWithETag(w, r, http.StatusOK, errorCodes)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"brew-manager/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	eventBufferSize     = 16
+	defaultSSEKeepAlive = 15 * time.Second
+)
+
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+type EventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+func (b *EventBroker) Subscribe() chan Event {
+	ch := make(chan Event, eventBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch
+}
+
+func (b *EventBroker) Unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	delete(b.subscribers, ch)
+	b.mu.Unlock()
+}
+
+func (b *EventBroker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logging.Warnf("Event subscriber is not keeping up, dropping %s event", event.Type)
+		}
+	}
+}
+
+func (h *Handler) Events() *EventBroker {
+	return h.events
+}
+
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	release, ok := h.acquireStream(w, r)
+	if !ok {
+		return
+	}
+	defer release()
+
+	stream, ok := startSSE(w, r)
+	if !ok {
+		return
+	}
+	defer stream.keepAlive(r.Context(), h.sseKeepAlive)()
+
+	ch := h.events.Subscribe()
+	defer h.events.Unsubscribe(ch)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-ch:
+			if err := stream.Send(event.Type, event.Data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *Handler) SetSSEKeepAlive(interval time.Duration) {
+	h.sseKeepAlive = interval
+}
+
+func (h *Handler) SetMaxStreams(n int) {
+	if n <= 0 {
+		h.streams = nil
+		return
+	}
+	h.streams = make(chan struct{}, n)
+}
+
+func (h *Handler) acquireStream(w http.ResponseWriter, r *http.Request) (func(), bool) {
+	if h.streams == nil {
+		return func() {}, true
+	}
+
+	select {
+	case h.streams <- struct{}{}:
+		return func() { <-h.streams }, true
+	default:
+		w.Header().Set("Retry-After", "10")
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable,
+			"Too many streaming connections are open. Close one and try again.",
+		)
+		return nil, false
+	}
+}
+
+type sseStream struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func startSSE(w http.ResponseWriter, r *http.Request) (*sseStream, bool) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		logFromCtx(r.Context()).Warnf("Failed to clear write deadline for event stream: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		logFromCtx(r.Context()).Errorf("Event stream does not support flushing: %v", err)
+		return nil, false
+	}
+	return &sseStream{w: w, rc: rc}, true
+}
+
+func (s *sseStream) Send(eventType string, data interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeSSEEvent(s.w, eventType, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (s *sseStream) heartbeat() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := fmt.Fprint(s.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (s *sseStream) keepAlive(ctx context.Context, interval time.Duration) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.heartbeat(); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+	return err
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"context"
+	"net/http"
+)
+
+type ExecRequest struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+type ExecResponse struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Output  string   `json:"output"`
+}
+
+func (h *Handler) ExecBrewCommand(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, r, http.StatusOK, brew.AllowedExecCommands())
+		return
+	}
+
+	var req ExecRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.Command == "" {
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Field 'command' is required",
+			map[string]string{"field": "command"},
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	output, err := h.brew.ExecReadOnly(ctx, req.Command, req.Args)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, ExecResponse{
+		Command: req.Command,
+		Args:    nonNil(req.Args),
+		Output:  output,
+	})
+}
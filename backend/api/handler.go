@@ -3,11 +3,17 @@ package api
 import (
 	"brew-manager/brew"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,6 +32,16 @@ const (
 	ErrCodeMethodNotAllow = "METHOD_NOT_ALLOWED"
 	ErrCodeTimeout        = "TIMEOUT"
 	ErrCodeInternal       = "INTERNAL_ERROR"
+	ErrCodeUnavailable    = "SERVICE_UNAVAILABLE"
+	ErrCodeBrewNotFound   = "BREW_NOT_FOUND"
+	ErrCodeReadOnly       = "READONLY_MODE"
+	ErrCodeRateLimited    = "RATE_LIMITED"
+	ErrCodeAmbiguous      = "AMBIGUOUS_PACKAGE"
+	ErrCodeConflict       = "CONFLICT"
+	ErrCodeForbidden      = "FORBIDDEN"
+	ErrCodeBrewLocked     = "BREW_LOCKED"
+	ErrCodeOutputTooLarge = "OUTPUT_TOO_LARGE"
+	ErrCodeUnauthorized   = "UNAUTHORIZED"
 )
 
 type SuccessResponse struct {
@@ -34,9 +50,49 @@ type SuccessResponse struct {
 }
 
 type PackageActionResponse struct {
-	Status  string `json:"status"`
-	Package string `json:"package"`
-	Action  string `json:"action,omitempty"`
+	Status   string   `json:"status"`
+	Package  string   `json:"package"`
+	Action   string   `json:"action,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to,omitempty"`
+	UpToDate bool     `json:"up_to_date,omitempty"`
+	Output   string   `json:"output,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Args     []string `json:"args,omitempty"`
+
+	NeedsRestart  bool   `json:"needs_restart,omitempty"`
+	PinnedVersion string `json:"pinned_version,omitempty"`
+	Forced        bool   `json:"forced,omitempty"`
+}
+
+type UninstallBatchRequest struct {
+	Names []string `json:"names"`
+	Force bool     `json:"force"`
+}
+
+type UpgradeSelectedRequest struct {
+	Names []string `json:"names"`
+}
+
+type ImportPlanResponse struct {
+	DryRun bool             `json:"dry_run"`
+	Plan   *brew.ImportPlan `json:"plan"`
+}
+
+type OutdatedGroup struct {
+	Count int                    `json:"count"`
+	Items []brew.OutdatedPackage `json:"items"`
+}
+
+type OutdatedGroupedResponse struct {
+	Formulae OutdatedGroup `json:"formulae"`
+	Casks    OutdatedGroup `json:"casks"`
+	Total    int           `json:"total"`
+}
+
+type PinBatchRequest struct {
+	Names  []string `json:"names"`
+	Action string   `json:"action"`
 }
 
 type ServiceActionResponse struct {
@@ -45,150 +101,1284 @@ type ServiceActionResponse struct {
 	Action  string `json:"action"`
 }
 
+type ServiceBulkResponse struct {
+	Status   string   `json:"status"`
+	Action   string   `json:"action"`
+	Affected []string `json:"affected"`
+	Count    int      `json:"count"`
+	Output   string   `json:"output"`
+}
+
 type SystemOperationResponse struct {
-	Message string `json:"message"`
-	Output  string `json:"output"`
+	Message    string `json:"message"`
+	Output     string `json:"output"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type UpdateCheckResponse struct {
+	UpdateAvailable bool   `json:"update_available"`
+	Summary         string `json:"summary"`
+}
+
+type ConflictsResponse struct {
+	Package      string   `json:"package"`
+	Conflicts    []string `json:"conflicts"`
+	HasConflicts bool     `json:"has_conflicts"`
+}
+
+type AnalyticsResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type DepsSizeResponse struct {
+	Package   string `json:"package"`
+	TotalSize int64  `json:"total_size"`
+}
+
+type CacheSizeResponse struct {
+	Bytes int64  `json:"bytes"`
+	Human string `json:"human"`
+}
+
+type DescribeResponse struct {
+	Name              string   `json:"name"`
+	FullName          string   `json:"full_name"`
+	Desc              string   `json:"desc"`
+	Homepage          string   `json:"homepage"`
+	StableVersion     string   `json:"stable_version"`
+	InstalledVersions []string `json:"installed_versions"`
+	Dependencies      []string `json:"dependencies"`
+	BuildDependencies []string `json:"build_dependencies"`
+	ConflictsWith     []string `json:"conflicts_with"`
+	Caveats           string   `json:"caveats"`
+	IsCask            bool     `json:"is_cask"`
+	ArchRequirement   string   `json:"arch_requirement,omitempty"`
+	RequiresRosetta   bool     `json:"requires_rosetta"`
+}
+
+type PackageListResponse struct {
+	Package  string   `json:"package"`
+	Packages []string `json:"packages"`
 }
 
 type UsageResponse struct {
 	Usage string `json:"usage"`
 }
 
+type ManPageResponse struct {
+	Page string `json:"page"`
+}
+
 type Handler struct {
 	brew           *brew.ServiceManager
 	requestTimeout time.Duration
+	ready          atomic.Bool
+	events         *EventBroker
+	settings       ServerSettings
+	sseKeepAlive   time.Duration
+	streams        chan struct{}
+	labels         *LabelStore
+	shutdown       *shutdownTrigger
+	routeTimeouts  map[string]time.Duration
+	stats          *RequestStats
+	writeTimeout   time.Duration
 }
 
 func NewHandler(b *brew.ServiceManager) *Handler {
-	return &Handler{
+	h := &Handler{
 		brew:           b,
-		requestTimeout: 5 * time.Minute, 
+		requestTimeout: defaultRequestTimeout,
+		events:         NewEventBroker(),
+		sseKeepAlive:   defaultSSEKeepAlive,
+		shutdown:       newShutdownTrigger(),
+	}
+	h.SetRouteTimeouts(nil)
+	return h
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	if wantsPrettyJSON(r) {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(data); err != nil {
+		logFromCtx(r.Context()).Errorf("Failed to encode JSON response: %v", err)
+
+	}
+}
+
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	var body []byte
+	var err error
+	if wantsPrettyJSON(r) {
+		body, err = json.MarshalIndent(data, "", "  ")
+	} else {
+		body, err = json.Marshal(data)
+	}
+	if err != nil {
+		logFromCtx(r.Context()).Errorf("Failed to encode JSON response: %v", err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to encode response")
+		return
+	}
+	body = append(body, '\n')
 
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(body); err != nil {
+		logFromCtx(r.Context()).Errorf("Failed to write JSON response: %v", err)
 	}
 }
 
-func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+func writeRawJSON(w http.ResponseWriter, r *http.Request, status int, body []byte) {
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		logFromCtx(r.Context()).Errorf("Failed to write JSON response: %v", err)
+	}
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeText(w http.ResponseWriter, r *http.Request, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.WriteHeader(status)
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("ERROR: Failed to encode JSON response: %v", err)
+	if _, err := io.WriteString(w, text); err != nil {
+		logFromCtx(r.Context()).Errorf("Failed to write text response: %v", err)
+	}
+}
 
+func wantsPlainText(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/plain":
+			return true
+		case "application/json", "*/*":
+			return false
+		}
 	}
+	return false
 }
 
-func writeError(w http.ResponseWriter, status int, code, message string) {
-	writeJSON(w, status, APIError{
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeJSON(w, r, status, APIError{
 		Error: message,
 		Code:  code,
 	})
 }
 
-func writeErrorWithDetails(w http.ResponseWriter, status int, code, message string, details map[string]string) {
-	writeJSON(w, status, APIError{
+func writeErrorWithDetails(w http.ResponseWriter, r *http.Request, status int, code, message string, details map[string]string) {
+	writeJSON(w, r, status, APIError{
 		Error:   message,
 		Code:    code,
 		Details: details,
 	})
 }
 
-func handleBrewError(w http.ResponseWriter, err error) {
+func handleBrewError(w http.ResponseWriter, r *http.Request, err error) {
 	if err == nil {
 		return
 	}
 
+	status, code, message, details := classifyBrewError(r.Context(), err)
+	writeErrorWithDetails(w, r, status, code, message, details)
+}
+
+func classifyBrewError(ctx context.Context, err error) (int, string, string, map[string]string) {
 	var validationErr *brew.ValidationError
 	var timeoutErr *brew.TimeoutError
 	var commandErr *brew.CommandError
+	var versionErr *brew.VersionNotAvailableError
+	var ambiguousErr *brew.AmbiguousPackageError
+	var installedErr *brew.AlreadyInstalledError
+	var notInstalledErr *brew.NotInstalledError
+	var notFoundErr *brew.PackageNotFoundError
+	var notAllowedErr *brew.CommandNotAllowedError
+	var serviceNotFoundErr *brew.ServiceNotFoundError
+	var partialErr *brew.PartialInstallError
+	var lockedErr *brew.BrewLockedError
+	var tooLargeErr *brew.OutputTooLargeError
 
 	switch {
 	case errors.As(err, &validationErr):
-		writeErrorWithDetails(w, http.StatusBadRequest, ErrCodeValidation,
+		return http.StatusBadRequest, ErrCodeValidation,
 			validationErr.Message,
-			map[string]string{"field": validationErr.Field},
-		)
+			map[string]string{"field": validationErr.Field}
+	case errors.As(err, &versionErr):
+		return http.StatusNotFound, ErrCodeNotFound,
+			versionErr.Error(),
+			map[string]string{"package": versionErr.Name, "version": versionErr.Version}
+	case errors.As(err, &installedErr):
+		return http.StatusConflict, ErrCodeConflict,
+			installedErr.Error() + "; use upgrade or reinstall instead",
+			map[string]string{"package": installedErr.Name}
+	case errors.As(err, &notAllowedErr):
+		return http.StatusForbidden, ErrCodeForbidden,
+			notAllowedErr.Error(),
+			map[string]string{"command": notAllowedErr.Command}
+	case errors.As(err, &partialErr):
+		logFromCtx(ctx).Errorf("Partial install of %s: %v", partialErr.Name, partialErr.Err)
+
+		return http.StatusInternalServerError, ErrCodeInternal,
+			"Install failed midway. Check server logs for details.",
+			map[string]string{"package": partialErr.Name, "cleanup_ran": strconv.FormatBool(partialErr.CleanedUp)}
+	case errors.As(err, &serviceNotFoundErr):
+		return http.StatusNotFound, ErrCodeNotFound,
+			serviceNotFoundErr.Error(),
+			map[string]string{"service": serviceNotFoundErr.Name}
+	case errors.As(err, &notFoundErr):
+		return http.StatusNotFound, ErrCodeNotFound,
+			notFoundErr.Error(),
+			map[string]string{"package": notFoundErr.Name}
+	case errors.As(err, &notInstalledErr):
+		return http.StatusNotFound, ErrCodeNotFound,
+			notInstalledErr.Error(),
+			map[string]string{"package": notInstalledErr.Name}
+	case errors.As(err, &ambiguousErr):
+		return http.StatusConflict, ErrCodeAmbiguous,
+			ambiguousErr.Error(),
+			map[string]string{"package": ambiguousErr.Name}
+	case errors.As(err, &lockedErr):
+		return http.StatusConflict, ErrCodeBrewLocked,
+			"Another Homebrew process is running on this machine. Wait for it to finish and try again.", nil
 	case errors.As(err, &timeoutErr):
-		writeError(w, http.StatusGatewayTimeout, ErrCodeTimeout,
-			"Operation timed out. The Homebrew command took too long to complete.",
-		)
+		return http.StatusGatewayTimeout, ErrCodeTimeout,
+			"Operation timed out. The Homebrew command took too long to complete.", nil
+	case errors.As(err, &tooLargeErr):
+		logFromCtx(ctx).Errorf("Brew output too large: %v", tooLargeErr)
+		return http.StatusBadGateway, ErrCodeOutputTooLarge,
+			"The Homebrew command produced more output than the server allows.",
+			map[string]string{"limit_bytes": strconv.FormatInt(tooLargeErr.Limit, 10)}
 	case errors.As(err, &commandErr):
 
-		log.Printf("Brew command error: %v", commandErr)
+		logFromCtx(ctx).Errorf("Brew command error: %v", commandErr)
 
-		writeError(w, http.StatusInternalServerError, ErrCodeInternal,
-			"Homebrew command failed. Check server logs for details.",
-		)
+		return http.StatusInternalServerError, ErrCodeInternal,
+			"Homebrew command failed. Check server logs for details.", nil
 	default:
-		log.Printf("Unexpected error: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternal,
-			"An unexpected error occurred.",
-		)
+		logFromCtx(ctx).Errorf("Unexpected error: %v", err)
+		return http.StatusInternalServerError, ErrCodeInternal,
+			"An unexpected error occurred.", nil
+	}
+}
+
+func (h *Handler) withPackageType(ctx context.Context, r *http.Request, name string, installedOnly bool) (context.Context, error) {
+	t, err := brew.ParsePackageType(r.URL.Query().Get("type"))
+	if err != nil {
+		return ctx, err
+	}
+	if t != "" {
+		return brew.WithPackageType(ctx, t), nil
+	}
+
+	ambiguous, err := h.brew.IsAmbiguous(ctx, name, installedOnly)
+	if err != nil {
+		return ctx, err
+	}
+	if ambiguous {
+		return ctx, &brew.AmbiguousPackageError{Name: name}
+	}
+	return ctx, nil
+}
+
+func checkMethod(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
+	for _, m := range allowed {
+		if r.Method == m {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllow,
+		"Method "+r.Method+" not allowed. Use: "+strings.Join(allowed, ", "),
+	)
+	return false
+}
+
+func (h *Handler) ListPackages(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	pkgs, err := h.brew.ListInstalled(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	h.writeInstalledList(w, r, pkgs)
+}
+
+func (h *Handler) ListPackagesLight(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	pkgs, err := h.brew.ListInstalledLight(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSONWithETag(w, r, http.StatusOK, pkgs)
+}
+
+func (h *Handler) ListFormulae(w http.ResponseWriter, r *http.Request) {
+	h.listInstalledByType(w, r, brew.PackageTypeFormula)
+}
+
+func (h *Handler) ListCasks(w http.ResponseWriter, r *http.Request) {
+	h.listInstalledByType(w, r, brew.PackageTypeCask)
+}
+
+func (h *Handler) listInstalledByType(w http.ResponseWriter, r *http.Request, t brew.PackageType) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	pkgs, err := h.brew.ListInstalled(brew.WithPackageType(ctx, t))
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	h.writeInstalledList(w, r, pkgs)
+}
+
+const (
+	defaultRecentDays = 7
+	maxRecentDays     = 365
+)
+
+func (h *Handler) RecentPackages(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	days := defaultRecentDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxRecentDays {
+			writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+				fmt.Sprintf("Query parameter 'days' must be an integer between 1 and %d", maxRecentDays),
+				map[string]string{"field": "days"},
+			)
+			return
+		}
+		days = n
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	pkgs, err := h.brew.ListInstalled(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, recentPackages(pkgs, time.Now().AddDate(0, 0, -days)))
+}
+
+func recentPackages(pkgs []brew.Package, since time.Time) []brew.Package {
+	recent := make([]brew.Package, 0)
+	for _, pkg := range pkgs {
+		if installedTime(pkg) >= since.Unix() {
+			recent = append(recent, pkg)
+		}
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool {
+		return installedTime(recent[i]) > installedTime(recent[j])
+	})
+	return recent
+}
+
+func installedTime(pkg brew.Package) int64 {
+	if len(pkg.Installed) == 0 {
+		return 0
+	}
+	return pkg.Installed[0].InstalledTime
+}
+
+func (h *Handler) UpgradePackage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return 
+
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	ctx, err := h.withPackageType(ctx, r, name, true)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	from, err := h.brew.InstalledVersion(ctx, name)
+	if err != nil {
+		logFromCtx(ctx).Warnf("Could not determine installed version of %s before upgrade: %v", name, err)
+	}
+
+	if err := h.brew.UpgradePackage(ctx, name); err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	to, err := h.brew.InstalledVersion(ctx, name)
+	if err != nil {
+		logFromCtx(ctx).Warnf("Could not determine installed version of %s after upgrade: %v", name, err)
+	}
+
+	upToDate := from != "" && from == to
+
+	var warnings []string
+	needsRestart := false
+	if !upToDate {
+		running, err := h.brew.IsServiceRunning(ctx, name)
+		if err != nil {
+			logFromCtx(ctx).Warnf("Could not check service status of %s after upgrade: %v", name, err)
+		} else if running {
+			needsRestart = true
+			warnings = append(warnings, "service "+name+" is still running the previous version; restart it to pick up the upgrade")
+		}
+	}
+
+	writeJSON(w, r, http.StatusOK, PackageActionResponse{
+		Status:       "success",
+		Package:      name,
+		Action:       "upgraded",
+		From:         from,
+		To:           to,
+		UpToDate:     upToDate,
+		Warnings:     warnings,
+		NeedsRestart: needsRestart,
+	})
+}
+
+func (h *Handler) GetOutdatedGrouped(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	outdated, err := h.brew.Outdated(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	resp := OutdatedGroupedResponse{
+		Formulae: OutdatedGroup{Items: []brew.OutdatedPackage{}},
+		Casks:    OutdatedGroup{Items: []brew.OutdatedPackage{}},
+		Total:    len(outdated),
+	}
+	for _, pkg := range outdated {
+		if pkg.IsCask {
+			resp.Casks.Items = append(resp.Casks.Items, pkg)
+		} else {
+			resp.Formulae.Items = append(resp.Formulae.Items, pkg)
+		}
+	}
+	resp.Formulae.Count = len(resp.Formulae.Items)
+	resp.Casks.Count = len(resp.Casks.Items)
+
+	writeJSONWithETag(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) UpgradePreview(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	plans, err := h.brew.UpgradePreview(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, plans)
+}
+
+func (h *Handler) UninstallPackage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodDelete, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	ctx, err := h.withPackageType(ctx, r, name, true)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	if err := h.brew.UninstallPackage(ctx, name, force); err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	var warnings []string
+	if force {
+		warnings = append(warnings, "forced removal: dependents of "+name+" may no longer work")
+	}
+
+	writeJSON(w, r, http.StatusOK, PackageActionResponse{
+		Status:   "success",
+		Package:  name,
+		Action:   "uninstalled",
+		Warnings: warnings,
+		Forced:   force,
+	})
+}
+
+func (h *Handler) ReinstallPackage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	var options []string
+	if raw := r.URL.Query().Get("options"); raw != "" {
+		for _, opt := range strings.Split(raw, ",") {
+			if opt = strings.TrimSpace(opt); opt != "" {
+				options = append(options, opt)
+			}
+		}
+	}
+
+	args, err := h.brew.ReinstallPackage(ctx, name, options...)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, PackageActionResponse{
+		Status:  "success",
+		Package: name,
+		Action:  "reinstalled",
+		Args:    args,
+	})
+}
+
+func (h *Handler) PinPackage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	action := r.URL.Query().Get("action")
+	if action == "" {
+		action = "pin" 
+
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	var pinnedVersion string
+	var err error
+	if action == "unpin" {
+		err = h.brew.UnpinPackage(ctx, name)
+	} else {
+		pinnedVersion, err = h.brew.PinPackage(ctx, name)
+	}
+
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, PackageActionResponse{
+		Status:        "success",
+		Package:       name,
+		Action:        action,
+		PinnedVersion: pinnedVersion,
+	})
+}
+
+func (h *Handler) PinBatch(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	var req PinBatchRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.Action == "" {
+		req.Action = "pin"
+	}
+	if req.Action != "pin" && req.Action != "unpin" {
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Invalid action. Must be one of: pin, unpin",
+			map[string]string{"action": req.Action},
+		)
+		return
+	}
+
+	if len(req.Names) == 0 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Field 'names' must contain at least one package")
+		return
+	}
+	for _, name := range req.Names {
+		if err := brew.ValidatePackageName(name); err != nil {
+			handleBrewError(w, r, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	batch := NewBatchResult(len(req.Names))
+	for _, name := range req.Names {
+		var err error
+		if req.Action == "unpin" {
+			err = h.brew.UnpinPackage(ctx, name)
+		} else {
+			_, err = h.brew.PinPackage(ctx, name)
+		}
+		batch.Add(ctx, name, err)
+	}
+
+	writeBatchResult(w, r, batch)
+}
+
+func (h *Handler) UninstallBatch(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	var req UninstallBatchRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if len(req.Names) == 0 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Field 'names' must contain at least one package")
+		return
+	}
+	for _, name := range req.Names {
+		if err := brew.ValidatePackageName(name); err != nil {
+			handleBrewError(w, r, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	inBatch := make(map[string]bool, len(req.Names))
+	for _, name := range req.Names {
+		inBatch[name] = true
+	}
+
+	batch := NewBatchResult(len(req.Names))
+	for _, name := range req.Names {
+		if !req.Force {
+			dependents, err := h.brew.Dependents(ctx, name)
+			if err != nil {
+				batch.Add(ctx, name, err)
+				continue
+			}
+
+			var blocking []string
+			for _, dep := range dependents {
+				if !inBatch[dep] {
+					blocking = append(blocking, dep)
+				}
+			}
+			if len(blocking) > 0 {
+				batch.AddSkipped(name, ErrCodeConflict, "still required by "+strings.Join(blocking, ", ")+"; set force to uninstall anyway")
+				continue
+			}
+		}
+
+		if err := h.brew.UninstallPackage(ctx, name, req.Force); err != nil {
+			batch.Add(ctx, name, err)
+			continue
+		}
+		if req.Force {
+			batch.AddSuccess(name, "force-uninstalled")
+		} else {
+			batch.AddSuccess(name, "uninstalled")
+		}
+	}
+
+	writeBatchResult(w, r, batch)
+}
+
+func (h *Handler) UpgradeSelected(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	var req UpgradeSelectedRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if len(req.Names) == 0 {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Field 'names' must contain at least one package")
+		return
+	}
+	for _, name := range req.Names {
+		if err := brew.ValidatePackageName(name); err != nil {
+			handleBrewError(w, r, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	pinned, err := h.brew.PinnedPackages(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+	isPinned := make(map[string]bool, len(pinned))
+	for _, name := range pinned {
+		isPinned[name] = true
+	}
+
+	seen := make(map[string]bool, len(req.Names))
+	batch := NewBatchResult(len(req.Names))
+	for _, name := range req.Names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if isPinned[name] {
+			batch.AddSkipped(name, ErrCodeConflict, "package is pinned; unpin it to upgrade")
+			continue
+		}
+
+		if err := h.brew.UpgradePackage(ctx, name); err != nil {
+			batch.Add(ctx, name, err)
+			continue
+		}
+		batch.AddSuccess(name, "upgraded")
+	}
+
+	writeBatchResult(w, r, batch)
+}
+
+func (h *Handler) GetPackageUsage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+
+	defer cancel()
+
+	usage, err := h.brew.GetPackageUsage(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	if wantsPlainText(r) {
+		writeText(w, r, http.StatusOK, usage)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, UsageResponse{Usage: usage})
+}
+
+func (h *Handler) CheckConflicts(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	conflicts, err := h.brew.CheckConflicts(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, ConflictsResponse{
+		Package:      name,
+		Conflicts:    conflicts,
+		HasConflicts: len(conflicts) > 0,
+	})
+}
+
+func (h *Handler) DescribePackage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	if raw, _ := strconv.ParseBool(r.URL.Query().Get("raw")); raw {
+		output, err := h.brew.InfoRaw(ctx, name)
+		if err != nil {
+			handleBrewError(w, r, err)
+			return
+		}
+		writeRawJSON(w, r, http.StatusOK, output)
+		return
+	}
+
+	pkg, err := h.brew.Info(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, describePackage(pkg))
+}
+
+func describePackage(pkg *brew.Package) DescribeResponse {
+	installed := make([]string, 0, len(pkg.Installed))
+	for _, inst := range pkg.Installed {
+		installed = append(installed, inst.Version)
+	}
+
+	return DescribeResponse{
+		Name:              pkg.Name,
+		FullName:          pkg.FullName,
+		Desc:              pkg.Desc,
+		Homepage:          pkg.Homepage,
+		StableVersion:     pkg.Versions.Stable,
+		InstalledVersions: installed,
+		Dependencies:      nonNil(pkg.Dependencies),
+		BuildDependencies: nonNil(pkg.BuildDependencies),
+		ConflictsWith:     nonNil(pkg.ConflictsWith),
+		Caveats:           pkg.Caveats,
+		IsCask:            pkg.IsCask,
+		ArchRequirement:   pkg.ArchRequirement,
+		RequiresRosetta:   pkg.RequiresRosetta,
+	}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func (h *Handler) GetDependencies(w http.ResponseWriter, r *http.Request) {
+	h.listRelated(w, r, h.brew.Dependencies)
+}
+
+func (h *Handler) GetDependents(w http.ResponseWriter, r *http.Request) {
+	h.listRelated(w, r, h.brew.Dependents)
+}
+
+func (h *Handler) listRelated(w http.ResponseWriter, r *http.Request, lookup func(context.Context, string) ([]string, error)) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	packages, err := lookup(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, PackageListResponse{
+		Package:  name,
+		Packages: nonNil(packages),
+	})
+}
+
+func (h *Handler) GetDepsSize(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	size, err := h.brew.DepsSize(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, DepsSizeResponse{
+		Package:   name,
+		TotalSize: size,
+	})
+}
+
+func (h *Handler) GetInstallPreview(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	ctx, err := h.withPackageType(ctx, r, name, false)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	preview, err := h.brew.InstallPreview(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, preview)
+}
+
+func (h *Handler) GetPopularity(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	popularity, err := h.brew.Popularity(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, popularity)
+}
+
+func (h *Handler) GetRemovable(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	removable, err := h.brew.Removable(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, removable)
+}
+
+func (h *Handler) GetInstallReason(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	reason, err := h.brew.Why(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, reason)
+}
+
+func (h *Handler) GetBuildLog(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+
+	buildLog, err := h.brew.BuildLog(r.Context(), name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	if wantsPlainText(r) {
+		if !buildLog.Available {
+			writeText(w, r, http.StatusOK, buildLog.Message)
+			return
+		}
+		writeText(w, r, http.StatusOK, buildLog.Log)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, buildLog)
+}
+
+func (h *Handler) GetReadme(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
 	}
-}
 
-func checkMethod(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
-	for _, m := range allowed {
-		if r.Method == m {
-			return true
-		}
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	readme, err := h.brew.Readme(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
 	}
 
-	w.Header().Set("Allow", strings.Join(allowed, ", "))
-	writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllow,
-		"Method "+r.Method+" not allowed. Use: "+strings.Join(allowed, ", "),
-	)
-	return false
+	writeJSON(w, r, http.StatusOK, readme)
 }
 
-func (h *Handler) ListPackages(w http.ResponseWriter, r *http.Request) {
-	if !checkMethod(w, r, http.MethodGet) {
+func (h *Handler) GetManPage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
-	defer cancel()
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
 
-	pkgs, err := h.brew.ListInstalled(ctx)
+	page, err := h.brew.ManPage(r.Context(), name)
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, pkgs)
+	writeJSON(w, r, http.StatusOK, ManPageResponse{Page: page})
 }
 
-func (h *Handler) UpgradePackage(w http.ResponseWriter, r *http.Request) {
-	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+func (h *Handler) SearchPackages(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
 		return
 	}
 	if r.Method == http.MethodOptions {
-		return 
+		return
+	}
 
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeJSON(w, r, http.StatusOK, []string{})
+		return
 	}
 
-	name := r.URL.Query().Get("name")
-	if name == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	results, err := h.brew.Search(ctx, query)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	if results == nil {
+		results = []string{}
+	}
+
+	writeJSON(w, r, http.StatusOK, results)
+}
+
+func (h *Handler) ListServices(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodHead) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	if err := h.brew.UpgradePackage(ctx, name); err != nil {
-		handleBrewError(w, err)
+	services, err := h.brew.ListServices(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, PackageActionResponse{
-		Status:  "success",
-		Package: name,
-		Action:  "upgraded",
-	})
+	writeJSONWithETag(w, r, http.StatusOK, services)
 }
 
-func (h *Handler) UninstallPackage(w http.ResponseWriter, r *http.Request) {
-	if !checkMethod(w, r, http.MethodDelete, http.MethodOptions) {
+func (h *Handler) GetServiceHealth(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
 		return
 	}
 	if r.Method == http.MethodOptions {
@@ -197,55 +1387,46 @@ func (h *Handler) UninstallPackage(w http.ResponseWriter, r *http.Request) {
 
 	name := r.URL.Query().Get("name")
 	if name == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	if err := h.brew.UninstallPackage(ctx, name); err != nil {
-		handleBrewError(w, err)
+	health, err := h.brew.ServiceHealth(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, PackageActionResponse{
-		Status:  "success",
-		Package: name,
-		Action:  "uninstalled",
-	})
+	writeJSON(w, r, http.StatusOK, health)
 }
 
-func (h *Handler) ReinstallPackage(w http.ResponseWriter, r *http.Request) {
-	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
-		return
-	}
-	if r.Method == http.MethodOptions {
+func (h *Handler) GetServiceDetail(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
 		return
 	}
 
 	name := r.URL.Query().Get("name")
 	if name == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	if err := h.brew.ReinstallPackage(ctx, name); err != nil {
-		handleBrewError(w, err)
+	service, err := h.brew.ServiceDetail(ctx, name)
+	if err != nil {
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, PackageActionResponse{
-		Status:  "success",
-		Package: name,
-		Action:  "reinstalled",
-	})
+	writeJSON(w, r, http.StatusOK, service)
 }
 
-func (h *Handler) PinPackage(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ControlService(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
 		return
 	}
@@ -254,114 +1435,181 @@ func (h *Handler) PinPackage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	name := r.URL.Query().Get("name")
+	action := r.URL.Query().Get("action")
+
 	if name == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
 		return
 	}
-
-	action := r.URL.Query().Get("action")
 	if action == "" {
-		action = "pin" 
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'action' is required")
+		return
+	}
 
+	if action != "start" && action != "stop" && action != "restart" {
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Invalid action. Must be one of: start, stop, restart",
+			map[string]string{"action": action},
+		)
+		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
 	var err error
-	if action == "unpin" {
-		err = h.brew.UnpinPackage(ctx, name)
-	} else {
-		err = h.brew.PinPackage(ctx, name)
+	switch action {
+	case "start":
+		err = h.brew.StartService(ctx, name)
+	case "stop":
+		err = h.brew.StopService(ctx, name)
+	case "restart":
+		err = h.brew.RestartService(ctx, name)
 	}
 
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, PackageActionResponse{
+	writeJSON(w, r, http.StatusOK, ServiceActionResponse{
 		Status:  "success",
-		Package: name,
+		Service: name,
 		Action:  action,
 	})
 }
 
-func (h *Handler) GetPackageUsage(w http.ResponseWriter, r *http.Request) {
-	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+func (h *Handler) RestartAllServices(w http.ResponseWriter, r *http.Request) {
+	h.controlAllServices(w, r, "restart", h.brew.RestartAll)
+}
+
+func (h *Handler) StopAllServices(w http.ResponseWriter, r *http.Request) {
+	h.controlAllServices(w, r, "stop", h.brew.StopAll)
+}
+
+func (h *Handler) controlAllServices(w http.ResponseWriter, r *http.Request, action string, run func(context.Context) (string, error)) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
 		return
 	}
 	if r.Method == http.MethodOptions {
 		return
 	}
 
-	name := r.URL.Query().Get("name")
-	if name == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	output, err := run(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second) 
+	affected := brew.ParseAffectedServices(output)
+	writeJSON(w, r, http.StatusOK, ServiceBulkResponse{
+		Status:   "success",
+		Action:   action,
+		Affected: affected,
+		Count:    len(affected),
+		Output:   output,
+	})
+}
+
+func (h *Handler) HandleSystemUpdate(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
 
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	usage, err := h.brew.GetPackageUsage(ctx, name)
+	start := time.Now()
+	output, err := h.brew.Update(ctx)
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, UsageResponse{Usage: usage})
+	writeJSON(w, r, http.StatusOK, SystemOperationResponse{
+		Message:    "Homebrew updated successfully",
+		Output:     output,
+		DurationMs: time.Since(start).Milliseconds(),
+	})
 }
 
-func (h *Handler) SearchPackages(w http.ResponseWriter, r *http.Request) {
-	if !checkMethod(w, r, http.MethodGet, http.MethodOptions) {
+func (h *Handler) HandleUpdateCheck(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
 		return
 	}
-	if r.Method == http.MethodOptions {
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	available, summary, err := h.brew.SelfUpdateAvailable(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
 		return
 	}
 
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		writeJSON(w, http.StatusOK, []string{})
+	writeJSON(w, r, http.StatusOK, UpdateCheckResponse{
+		UpdateAvailable: available,
+		Summary:         summary,
+	})
+}
+
+func (h *Handler) GetCacheSize(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	results, err := h.brew.Search(ctx, query)
+	size, err := h.brew.CacheSize(ctx)
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	if results == nil {
-		results = []string{}
+	writeJSON(w, r, http.StatusOK, CacheSizeResponse{
+		Bytes: size,
+		Human: brew.FormatBytes(size),
+	})
+}
+
+func (h *Handler) ListOperations(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
 	}
 
-	writeJSON(w, http.StatusOK, results)
+	writeJSON(w, r, http.StatusOK, h.brew.Operations())
 }
 
-func (h *Handler) ListServices(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetDiagnostics(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, http.MethodGet) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	services, err := h.brew.ListServices(ctx)
-	if err != nil {
-		handleBrewError(w, err)
+	writeJSON(w, r, http.StatusOK, h.brew.Diagnostics(ctx))
+}
+
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, services)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	writeJSON(w, r, http.StatusOK, h.brew.Export(ctx))
 }
 
-func (h *Handler) ControlService(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
 		return
 	}
@@ -369,52 +1617,127 @@ func (h *Handler) ControlService(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	name := r.URL.Query().Get("name")
-	action := r.URL.Query().Get("action")
+	var doc brew.ExportDocument
+	if !decodeJSONBodyLimit(w, r, &doc, maxImportBodyBytes) {
+		return
+	}
 
-	if name == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	plan, err := h.brew.PlanImport(ctx, &doc)
+	if err != nil {
+		handleBrewError(w, r, err)
 		return
 	}
-	if action == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'action' is required")
+
+	apply, _ := strconv.ParseBool(r.URL.Query().Get("apply"))
+	if !apply {
+		writeJSON(w, r, http.StatusOK, ImportPlanResponse{DryRun: true, Plan: plan})
 		return
 	}
 
-	if action != "start" && action != "stop" && action != "restart" {
-		writeErrorWithDetails(w, http.StatusBadRequest, ErrCodeValidation,
-			"Invalid action. Must be one of: start, stop, restart",
-			map[string]string{"action": action},
-		)
+	batch := NewBatchResult(len(plan.Taps) + len(plan.Install) + len(plan.Pin) + len(plan.StartServices))
+	for _, tap := range plan.Taps {
+		if err := h.brew.AddTap(ctx, tap); err != nil {
+			batch.Add(ctx, "tap:"+tap, err)
+			continue
+		}
+		batch.AddSuccess("tap:"+tap, "tapped")
+	}
+	for _, pkg := range plan.Install {
+		pkgCtx := brew.WithPackageType(ctx, brew.PackageTypeFormula)
+		if pkg.IsCask {
+			pkgCtx = brew.WithPackageType(ctx, brew.PackageTypeCask)
+		}
+		if _, err := h.brew.InstallPackage(pkgCtx, pkg.Name, false); err != nil {
+			var installedErr *brew.AlreadyInstalledError
+			if errors.As(err, &installedErr) {
+				batch.AddSkipped("install:"+pkg.Name, ErrCodeConflict, "already installed")
+				continue
+			}
+			batch.Add(ctx, "install:"+pkg.Name, err)
+			continue
+		}
+		batch.AddSuccess("install:"+pkg.Name, "installed")
+	}
+	for _, name := range plan.Pin {
+		if _, err := h.brew.PinPackage(ctx, name); err != nil {
+			batch.Add(ctx, "pin:"+name, err)
+			continue
+		}
+		batch.AddSuccess("pin:"+name, "pinned")
+	}
+	for _, name := range plan.StartServices {
+		if err := h.brew.StartService(ctx, name); err != nil {
+			batch.Add(ctx, "service:"+name, err)
+			continue
+		}
+		batch.AddSuccess("service:"+name, "started")
+	}
+
+	writeBatchResult(w, r, batch)
+}
+
+func (h *Handler) HandleSystemCleanup(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	var err error
-	switch action {
-	case "start":
-		err = h.brew.StartService(ctx, name)
-	case "stop":
-		err = h.brew.StopService(ctx, name)
-	case "restart":
-		err = h.brew.RestartService(ctx, name)
+	start := time.Now()
+	output, err := h.brew.Cleanup(ctx)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, SystemOperationResponse{
+		Message:    "Cleanup completed successfully",
+		Output:     output,
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+}
+
+func (h *Handler) HandleAnalytics(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	if r.Method == http.MethodPost {
+		enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'enabled' must be true or false")
+			return
+		}
+
+		if err := h.brew.SetAnalytics(ctx, enabled); err != nil {
+			handleBrewError(w, r, err)
+			return
+		}
 	}
 
+	enabled, err := h.brew.GetAnalytics(ctx)
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, ServiceActionResponse{
-		Status:  "success",
-		Service: name,
-		Action:  action,
-	})
+	writeJSON(w, r, http.StatusOK, AnalyticsResponse{Enabled: enabled})
 }
 
-func (h *Handler) HandleSystemUpdate(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) HandleDoctor(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
 		return
 	}
@@ -422,22 +1745,30 @@ func (h *Handler) HandleSystemUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	output, err := h.brew.Update(ctx)
+	start := time.Now()
+	output, issues, err := h.brew.Doctor(ctx)
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, SystemOperationResponse{
-		Message: "Homebrew updated successfully",
-		Output:  output,
+	if wantsPlainText(r) {
+		writeText(w, r, http.StatusOK, output)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"output":      output,
+		"issues":      issues,
+		"isHealthy":   len(issues) == 0,
+		"duration_ms": time.Since(start).Milliseconds(),
 	})
 }
 
-func (h *Handler) HandleSystemCleanup(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) HandleDoctorFix(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
 		return
 	}
@@ -445,22 +1776,24 @@ func (h *Handler) HandleSystemCleanup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	output, err := h.brew.Cleanup(ctx)
+	result, err := h.brew.FixDoctorIssues(ctx)
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, SystemOperationResponse{
-		Message: "Cleanup completed successfully",
-		Output:  output,
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"fixed":         result.Fixed,
+		"failed":        result.Failed,
+		"unfixed":       result.Unfixed,
+		"fixable_types": brew.FixableDoctorTypes(),
 	})
 }
 
-func (h *Handler) HandleDoctor(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) InstallVersion(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
 		return
 	}
@@ -468,19 +1801,32 @@ func (h *Handler) HandleDoctor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	name := r.URL.Query().Get("name")
+	version := r.URL.Query().Get("version")
+	if name == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'name' is required")
+		return
+	}
+	if version == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Query parameter 'version' is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	output, issues, err := h.brew.Doctor(ctx)
+	output, err := h.brew.InstallVersion(ctx, name, version)
 	if err != nil {
-		handleBrewError(w, err)
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"output":    output,
-		"issues":    issues,
-		"isHealthy": len(issues) == 0,
+	writeJSON(w, r, http.StatusOK, PackageActionResponse{
+		Status:  "success",
+		Package: name,
+		Action:  "installed",
+		To:      version,
+		Output:  output,
 	})
 }
 
@@ -506,22 +1852,44 @@ func (h *Handler) InstallPackage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if name == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidation, "Package name is required")
+		writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "Package name is required")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
 	defer cancel()
 
-	if err := h.brew.InstallPackage(ctx, name); err != nil {
-		handleBrewError(w, err)
+	ctx, err := h.withPackageType(ctx, r, name, false)
+	if err != nil {
+		handleBrewError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, PackageActionResponse{
-		Status:  "success",
-		Package: name,
-		Action:  "installed",
+	var outputs, warnings []string
+	if update, _ := strconv.ParseBool(r.URL.Query().Get("update")); update {
+		output, err := h.brew.Update(ctx)
+		if err != nil {
+			logFromCtx(ctx).Warnf("brew update before installing %s failed: %v", name, err)
+			warnings = append(warnings, "brew update failed; installed using existing formula definitions")
+		} else {
+			outputs = append(outputs, output)
+		}
+	}
+
+	cleanupOnFailure, _ := strconv.ParseBool(r.URL.Query().Get("cleanup_on_failure"))
+	output, err := h.brew.InstallPackage(ctx, name, cleanupOnFailure)
+	if err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+	outputs = append(outputs, output)
+
+	writeJSON(w, r, http.StatusOK, PackageActionResponse{
+		Status:   "success",
+		Package:  name,
+		Action:   "installed",
+		Output:   strings.Join(outputs, "\n"),
+		Warnings: warnings,
 	})
 }
 
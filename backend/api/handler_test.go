@@ -0,0 +1,18 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"brew-manager/brew"
+)
+
+func TestClassifyBrewLockedError(t *testing.T) {
+	err := &brew.BrewLockedError{Command: "install"}
+
+	status, code, _, _ := classifyBrewError(context.Background(), err)
+	if status != http.StatusConflict || code != ErrCodeBrewLocked {
+		t.Fatalf("classifyBrewError = %d %s, want %d %s", status, code, http.StatusConflict, ErrCodeBrewLocked)
+	}
+}
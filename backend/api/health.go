@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const readinessTimeout = 5 * time.Second
+
+type HealthResponse struct {
+	Status      string `json:"status"`
+	BrewVersion string `json:"brew_version,omitempty"`
+}
+
+func (h *Handler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+func (h *Handler) Livez(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, HealthResponse{Status: "alive"})
+}
+
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	if !h.ready.Load() {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, "Server is not ready to serve requests")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	version, err := h.brew.Version(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, "Homebrew is not reachable")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, HealthResponse{
+		Status:      "ready",
+		BrewVersion: version,
+	})
+}
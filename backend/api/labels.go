@@ -0,0 +1,252 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+const maxLabelsPerPackage = 16
+
+var labelRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,31}$`)
+
+type LabelStore struct {
+	path   string
+	mu     sync.RWMutex
+	labels map[string][]string
+}
+
+type SetLabelsRequest struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+}
+
+type LabelsResponse struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+}
+
+func NewLabelStore(path string) (*LabelStore, error) {
+	s := &LabelStore{
+		path:   path,
+		labels: make(map[string][]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read label store %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.labels); err != nil {
+		return nil, fmt.Errorf("failed to parse label store %q: %w", path, err)
+	}
+	return s, nil
+}
+
+func DefaultLabelStorePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "brew-manager", "labels.json"), nil
+}
+
+func (s *LabelStore) Get(name string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string{}, s.labels[name]...)
+}
+
+func (s *LabelStore) All() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make(map[string][]string, len(s.labels))
+	for name, labels := range s.labels {
+		all[name] = append([]string{}, labels...)
+	}
+	return all
+}
+
+func (s *LabelStore) HasLabel(name, label string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, l := range s.labels[name] {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *LabelStore) Set(name string, labels []string) ([]string, error) {
+	normalized := normalizeLabels(labels)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.labels[name]
+	if len(normalized) == 0 {
+		delete(s.labels, name)
+	} else {
+		s.labels[name] = normalized
+	}
+
+	if err := s.save(); err != nil {
+		if existed {
+			s.labels[name] = previous
+		} else {
+			delete(s.labels, name)
+		}
+		return nil, err
+	}
+	return append([]string{}, normalized...), nil
+}
+
+func (s *LabelStore) save() error {
+	data, err := json.MarshalIndent(s.labels, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create label store directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".labels-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write label store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write label store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write label store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write label store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace label store: %w", err)
+	}
+	return nil
+}
+
+func normalizeLabels(labels []string) []string {
+	seen := make(map[string]bool, len(labels))
+	normalized := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if seen[label] {
+			continue
+		}
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func validateLabels(labels []string) error {
+	if len(labels) > maxLabelsPerPackage {
+		return &brew.ValidationError{
+			Field:   "labels",
+			Message: fmt.Sprintf("at most %d labels are allowed per package", maxLabelsPerPackage),
+		}
+	}
+	for _, label := range labels {
+		if !labelRegex.MatchString(label) {
+			return &brew.ValidationError{
+				Field:   "labels",
+				Value:   label,
+				Message: "label must match pattern: " + labelRegex.String(),
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Handler) SetLabelStore(s *LabelStore) {
+	h.labels = s
+}
+
+func (h *Handler) HandleLabels(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	if h.labels == nil {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, "Label store is not configured")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		if name := r.URL.Query().Get("name"); name != "" {
+			writeJSON(w, r, http.StatusOK, LabelsResponse{Name: name, Labels: h.labels.Get(name)})
+			return
+		}
+		writeJSON(w, r, http.StatusOK, h.labels.All())
+		return
+	}
+
+	var req SetLabelsRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if err := brew.ValidatePackageName(req.Name); err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+	if err := validateLabels(req.Labels); err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	labels, err := h.labels.Set(req.Name, req.Labels)
+	if err != nil {
+		logFromCtx(r.Context()).Errorf("Failed to save labels for %s: %v", req.Name, err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Failed to save labels")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, LabelsResponse{Name: req.Name, Labels: labels})
+}
+
+func (h *Handler) filterByLabel(r *http.Request, pkgs []brew.Package) ([]brew.Package, bool) {
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		return pkgs, true
+	}
+	if h.labels == nil {
+		return []brew.Package{}, true
+	}
+	if !labelRegex.MatchString(label) {
+		return nil, false
+	}
+
+	filtered := make([]brew.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if h.labels.HasLabel(pkg.Name, label) {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered, true
+}
@@ -1,9 +1,13 @@
 package api
 
 import (
-	"log"
+	"brew-manager/logging"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"time"
 )
@@ -48,12 +52,16 @@ func CORSMiddleware(next http.Handler, cfg CORSConfig) http.Handler {
 				allowedOrigin = "*"
 				break
 			}
-			if o == origin {
+			if origin != "" && matchOrigin(o, origin) {
 				allowedOrigin = origin
 				break
 			}
 		}
 
+		if allowedOrigin != "*" {
+			w.Header().Add("Vary", "Origin")
+		}
+
 		if allowedOrigin != "" {
 			w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
 			if cfg.AllowCredentials && allowedOrigin != "*" {
@@ -75,9 +83,30 @@ func CORSMiddleware(next http.Handler, cfg CORSConfig) http.Handler {
 	})
 }
 
+func matchOrigin(pattern, origin string) bool {
+	if pattern == origin {
+		return true
+	}
+
+	patternScheme, patternHost, ok := strings.Cut(pattern, "://")
+	if !ok || !strings.HasPrefix(patternHost, "*.") {
+		return false
+	}
+
+	originScheme, originHost, ok := strings.Cut(origin, "://")
+	if !ok || originScheme != patternScheme {
+		return false
+	}
+
+	suffix := patternHost[1:]
+	sub, found := strings.CutSuffix(originHost, suffix)
+	return found && sub != "" && !strings.Contains(sub, "/")
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	status      int
+	bytes       int
 	wroteHeader bool
 }
 
@@ -97,10 +126,24 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	if !rw.wroteHeader {
 		rw.WriteHeader(http.StatusOK)
 	}
-	return rw.ResponseWriter.Write(b)
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+type LoggingConfig struct {
+	RedactQuery bool
 }
 
 func LoggingMiddleware(next http.Handler) http.Handler {
+	return LoggingMiddlewareWithConfig(next, LoggingConfig{})
+}
+
+func LoggingMiddlewareWithConfig(next http.Handler, cfg LoggingConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -108,23 +151,60 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(wrapped, r)
 
 		duration := time.Since(start)
+		path := logPath(r, cfg.RedactQuery)
+
+		ip := clientIP(r)
 
 		if wrapped.status >= 500 {
-			log.Printf("ERROR: %s %s %d %v", r.Method, r.URL.Path, wrapped.status, duration)
+			logFromCtx(r.Context()).Always(logging.LevelError, "%s %s %s %d %dB %v", ip, r.Method, path, wrapped.status, wrapped.bytes, duration)
 		} else if wrapped.status >= 400 {
-			log.Printf("WARN: %s %s %d %v", r.Method, r.URL.Path, wrapped.status, duration)
+			logFromCtx(r.Context()).Always(logging.LevelWarn, "%s %s %s %d %dB %v", ip, r.Method, path, wrapped.status, wrapped.bytes, duration)
 		} else {
-			log.Printf("INFO: %s %s %d %v", r.Method, r.URL.Path, wrapped.status, duration)
+			logFromCtx(r.Context()).Infof("%s %s %s %d %dB %v", ip, r.Method, path, wrapped.status, wrapped.bytes, duration)
 		}
 	})
 }
 
+func LoggingMiddlewareFunc(cfg LoggingConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return LoggingMiddlewareWithConfig(next, cfg)
+	}
+}
+
+const redactedValue = "[redacted]"
+
+func logPath(r *http.Request, redact bool) string {
+	path := r.URL.Path
+	if !redact {
+		return path
+	}
+
+	if rest, ok := strings.CutPrefix(path, "/api/packages/"); ok {
+		if parts := strings.Split(rest, "/"); len(parts) >= 2 {
+			parts[0] = redactedValue
+			path = "/api/packages/" + strings.Join(parts, "/")
+		}
+	}
+
+	query := r.URL.Query()
+	if len(query) == 0 {
+		return path
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k+"="+redactedValue)
+	}
+	sort.Strings(keys)
+	return path + "?" + strings.Join(keys, "&")
+}
+
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
 
-				log.Printf("PANIC: %v\n%s", err, debug.Stack())
+				logFromCtx(r.Context()).Errorf("PANIC: %v\n%s", err, debug.Stack())
 
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusInternalServerError)
@@ -149,3 +229,39 @@ func CORSMiddlewareFunc(cfg CORSConfig) func(http.Handler) http.Handler {
 	}
 }
 
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+const requestIDHeader = "X-Request-ID"
+
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		ctx = logging.NewContext(ctx, logging.NewLogger(id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func logFromCtx(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx)
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"brew-manager/logging"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestLogPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		redact bool
+		want   string
+	}{
+		{"plain path", "/api/packages", false, "/api/packages"},
+		{"query omitted by default", "/api/packages/search?q=secret", false, "/api/packages/search"},
+		{"query values redacted", "/api/packages/search?q=secret", true, "/api/packages/search?q=[redacted]"},
+		{"package segment redacted", "/api/packages/wget/upgrade", true, "/api/packages/[redacted]/upgrade"},
+		{"redacted without query", "/api/services", true, "/api/services"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := logPath(httptest.NewRequest("GET", tt.target, nil), tt.redact)
+			if got != tt.want {
+				t.Errorf("logPath(%q, %v) = %q, want %q", tt.target, tt.redact, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		{"https://app.example.com", "https://app.example.com", true},
+		{"https://app.example.com", "https://other.example.com", false},
+		{"https://*.example.com", "https://app.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "http://app.example.com", false},
+		{"https://*.example.com", "https://app.example.com.evil.net", false},
+		{"https://*.example.com", "https://evilexample.com", false},
+		{"https://*.example.com", "app.example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchOrigin(tt.pattern, tt.origin); got != tt.want {
+			t.Errorf("matchOrigin(%q, %q) = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSMiddlewareReflectsWildcardSubdomain(t *testing.T) {
+	handler := CORSMiddleware(okHandler, CORSConfig{AllowedOrigins: []string{"https://*.example.com"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/packages", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want reflected origin", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/packages", nil)
+	req.Header.Set("Origin", "http://app.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q for scheme mismatch, want empty", got)
+	}
+}
+
+func TestCORSMiddlewareVaryOrigin(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		wantVary bool
+	}{
+		{"reflected origin", []string{"https://app.example.com"}, true},
+		{"wildcard", []string{"*"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := CORSMiddleware(okHandler, CORSConfig{AllowedOrigins: tt.allowed})
+			req := httptest.NewRequest(http.MethodGet, "/api/packages", nil)
+			req.Header.Set("Origin", "https://app.example.com")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Vary") == "Origin"; got != tt.wantVary {
+				t.Errorf("Vary: Origin present = %v, want %v", got, tt.wantVary)
+			}
+		})
+	}
+}
+
+func TestResponseWriterCountsBytes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	wrapped := wrapResponseWriter(rec)
+
+	wrapped.Write([]byte(`{"packages":`))
+	wrapped.Write([]byte(`[]}`))
+
+	if wrapped.bytes != rec.Body.Len() {
+		t.Errorf("bytes = %d, want %d", wrapped.bytes, rec.Body.Len())
+	}
+	if wrapped.status != http.StatusOK {
+		t.Errorf("status = %d, want %d", wrapped.status, http.StatusOK)
+	}
+}
+
+func TestLoggingMiddlewareAlwaysLogsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	logging.SetLevel(logging.LevelError)
+	defer logging.SetLevel(logging.LevelInfo)
+
+	tests := []struct {
+		status  int
+		wantLog string
+	}{
+		{http.StatusOK, ""},
+		{http.StatusNotFound, "WARN: "},
+		{http.StatusInternalServerError, "ERROR: "},
+	}
+
+	for _, tt := range tests {
+		buf.Reset()
+		handler := RequestIDMiddleware(LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		})))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/packages", nil))
+
+		got := buf.String()
+		if tt.wantLog == "" && got != "" {
+			t.Errorf("status %d logged %q at LOG_LEVEL=error", tt.status, got)
+		}
+		if tt.wantLog != "" && !strings.Contains(got, tt.wantLog) {
+			t.Errorf("status %d logged %q, want a %s line", tt.status, got, tt.wantLog)
+		}
+	}
+}
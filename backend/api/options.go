@@ -0,0 +1,29 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"net/http"
+	"strconv"
+)
+
+func BrewOptionsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if prefix := query.Get("prefix"); prefix != "" {
+			r = r.WithContext(brew.WithPrefix(r.Context(), prefix))
+		}
+		if noAutoUpdate, _ := strconv.ParseBool(query.Get("no_auto_update")); noAutoUpdate {
+			r = r.WithContext(brew.WithNoAutoUpdate(r.Context()))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) ListPrefixes(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, h.brew.Prefixes())
+}
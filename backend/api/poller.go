@@ -0,0 +1,79 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"brew-manager/logging"
+	"context"
+	"time"
+)
+
+const outdatedPollTimeout = 2 * time.Minute
+
+type OutdatedCountEvent struct {
+	Count int `json:"count"`
+}
+
+type OutdatedPoller struct {
+	brew      *brew.ServiceManager
+	events    *EventBroker
+	interval  time.Duration
+	lastCount int
+}
+
+func NewOutdatedPoller(b *brew.ServiceManager, events *EventBroker, interval time.Duration) *OutdatedPoller {
+	return &OutdatedPoller{
+		brew:      b,
+		events:    events,
+		interval:  interval,
+		lastCount: -1,
+	}
+}
+
+func (p *OutdatedPoller) Start(ctx context.Context) {
+	if p.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		p.poll(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.poll(ctx)
+			}
+		}
+	}()
+}
+
+func (p *OutdatedPoller) poll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, outdatedPollTimeout)
+	defer cancel()
+
+	var outdated []brew.OutdatedPackage
+	err := p.brew.WithOperation(ctx, "outdated-poll", "", func() error {
+		var err error
+		outdated, err = p.brew.Outdated(ctx)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			logging.Warnf("Outdated poll failed: %v", err)
+		}
+		return
+	}
+
+	if len(outdated) == p.lastCount {
+		return
+	}
+	p.lastCount = len(outdated)
+
+	p.events.Publish(Event{
+		Type: "outdated",
+		Data: OutdatedCountEvent{Count: len(outdated)},
+	})
+}
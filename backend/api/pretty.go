@@ -0,0 +1,24 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+const prettyJSONKey contextKey = "pretty_json"
+
+func PrettyJSONMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty")); pretty {
+			r = r.WithContext(context.WithValue(r.Context(), prettyJSONKey, true))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func wantsPrettyJSON(r *http.Request) bool {
+	pretty, _ := r.Context().Value(prettyJSONKey).(bool)
+	return pretty
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrettyJSONMiddleware(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantIndent bool
+	}{
+		{"/api/config", false},
+		{"/api/config?pretty=true", true},
+		{"/api/config?pretty=0", false},
+	}
+
+	for _, tt := range tests {
+		// The logging wrapper sits between the middleware and the handler,
+		// as in the real chain; the flag must survive it.
+		handler := PrettyJSONMiddleware(LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+		})))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		if got := strings.Contains(rec.Body.String(), "\n  "); got != tt.wantIndent {
+			t.Errorf("%s: indented = %v, want %v (body %q)", tt.target, got, tt.wantIndent, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+var readOnlyBlockedOperations = map[string]bool{
+	"packages/install":          true,
+	"packages/install-stream":   true,
+	"packages/install-version":  true,
+	"packages/uninstall-batch":  true,
+	"packages/uninstall":        true,
+	"packages/upgrade":          true,
+	"packages/upgrade-selected": true,
+	"packages/reinstall":        true,
+	"packages/pin":              true,
+	"packages/labels":           true,
+	"packages/pin-batch":        true,
+	"services/control":          true,
+	"services/restart-all":      true,
+	"services/stop-all":         true,
+	"update":                    true,
+	"cleanup":                   true,
+	"system/update":             true,
+	"system/cleanup":            true,
+	"system/analytics":          true,
+	"doctor/fix":                true,
+}
+
+func IsReadOnlyBlocked(r *http.Request) bool {
+	return isMutatingOperation(r)
+}
+
+// isMutatingOperation reports whether r changes Homebrew or server state, as
+// opposed to a POST that only reads, such as an import preview.
+func isMutatingOperation(r *http.Request) bool {
+	if !isMutatingMethod(r.Method) {
+		return false
+	}
+
+	operation, _ := routeOperation(r)
+	if operation == "import" {
+		apply, _ := strconv.ParseBool(r.URL.Query().Get("apply"))
+		return apply
+	}
+	return readOnlyBlockedOperations[operation]
+}
+
+func ReadOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsReadOnlyBlocked(r) {
+			writeError(w, r, http.StatusForbidden, ErrCodeReadOnly,
+				"This server is running in read-only mode; mutating operations are disabled",
+			)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadOnlyMiddlewareBlocksMutatingRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{"install stream", http.MethodPost, "/api/packages/install-stream?name=wget", http.StatusForbidden},
+		{"install version", http.MethodPost, "/api/packages/install-version?name=node&version=18", http.StatusForbidden},
+		{"install", http.MethodPost, "/api/packages/install?name=wget", http.StatusForbidden},
+		{"path style upgrade", http.MethodPost, "/api/packages/wget/upgrade", http.StatusForbidden},
+		{"list", http.MethodGet, "/api/packages", http.StatusOK},
+		{"search", http.MethodGet, "/api/packages/search?q=wget", http.StatusOK},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := ReadOnlyMiddleware(next)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rec.Code != tt.want {
+				t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"brew-manager/logging"
+	"context"
+	"time"
+)
+
+const scheduledCleanupTimeout = 30 * time.Minute
+
+type CleanupScheduler struct {
+	brew     *brew.ServiceManager
+	interval time.Duration
+}
+
+func NewCleanupScheduler(b *brew.ServiceManager, interval time.Duration) *CleanupScheduler {
+	return &CleanupScheduler{
+		brew:     b,
+		interval: interval,
+	}
+}
+
+func (c *CleanupScheduler) Start(ctx context.Context) {
+	if c.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.run(ctx)
+			}
+		}
+	}()
+}
+
+func (c *CleanupScheduler) run(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, scheduledCleanupTimeout)
+	defer cancel()
+
+	logging.Infof("Running scheduled brew cleanup")
+	start := time.Now()
+	output, err := c.brew.Cleanup(ctx)
+	c.brew.NotifyWebhook(brew.NewWebhookEvent("system/cleanup:scheduled", "", start, err))
+	if err != nil {
+		if ctx.Err() == nil {
+			logging.Errorf("Scheduled cleanup failed: %v", err)
+		}
+		return
+	}
+
+	if freed := brew.ParseFreedSpace(output); freed != "" {
+		logging.Infof("Scheduled cleanup freed approximately %s", freed)
+	} else {
+		logging.Infof("Scheduled cleanup completed; nothing to free")
+	}
+}
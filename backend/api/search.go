@@ -0,0 +1,93 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"net/http"
+	"strings"
+)
+
+var installedSearchFields = []string{"name", "desc"}
+
+type InstalledMatch struct {
+	brew.Package
+	Matched []string `json:"matched"`
+}
+
+func (h *Handler) writeInstalledList(w http.ResponseWriter, r *http.Request, pkgs []brew.Package) {
+	pkgs, ok := h.filterByLabel(r, pkgs)
+	if !ok {
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Query parameter 'label' must match pattern: "+labelRegex.String(),
+			map[string]string{"field": "label"},
+		)
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeJSONWithETag(w, r, http.StatusOK, pkgs)
+		return
+	}
+
+	fields, ok := parseSearchFields(r.URL.Query().Get("in"))
+	if !ok {
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Query parameter 'in' must be a comma-separated list of: "+strings.Join(installedSearchFields, ", "),
+			map[string]string{"field": "in"},
+		)
+		return
+	}
+
+	writeJSONWithETag(w, r, http.StatusOK, searchInstalled(pkgs, q, fields))
+}
+
+func parseSearchFields(raw string) ([]string, bool) {
+	if strings.TrimSpace(raw) == "" {
+		return installedSearchFields, true
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+		if field != "name" && field != "desc" {
+			return nil, false
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, len(fields) > 0
+}
+
+func searchInstalled(pkgs []brew.Package, query string, fields []string) []InstalledMatch {
+	query = strings.ToLower(query)
+
+	matches := make([]InstalledMatch, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		var matched []string
+		for _, field := range fields {
+			if packageFieldMatches(pkg, field, query) {
+				matched = append(matched, field)
+			}
+		}
+		if len(matched) > 0 {
+			matches = append(matches, InstalledMatch{Package: pkg, Matched: matched})
+		}
+	}
+	return matches
+}
+
+func packageFieldMatches(pkg brew.Package, field, query string) bool {
+	switch field {
+	case "name":
+		return strings.Contains(strings.ToLower(pkg.Name), query) ||
+			strings.Contains(strings.ToLower(pkg.FullName), query)
+	case "desc":
+		return strings.Contains(strings.ToLower(pkg.Desc), query)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type shutdownTrigger struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func newShutdownTrigger() *shutdownTrigger {
+	return &shutdownTrigger{ch: make(chan struct{})}
+}
+
+func (t *shutdownTrigger) fire() {
+	t.once.Do(func() { close(t.ch) })
+}
+
+func (h *Handler) ShutdownRequested() <-chan struct{} {
+	return h.shutdown.ch
+}
+
+func TokenAuth(next http.HandlerFunc, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next(w, r)
+			return
+		}
+		if token == "" {
+			writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "This endpoint is disabled")
+			return
+		}
+
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="brew-manager"`)
+			writeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "A valid bearer token is required")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *Handler) HandleShutdown(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	if confirm, _ := strconv.ParseBool(r.URL.Query().Get("confirm")); !confirm {
+		writeErrorWithDetails(w, r, http.StatusBadRequest, ErrCodeValidation,
+			"Shutdown requires confirm=true",
+			map[string]string{"field": "confirm"},
+		)
+		return
+	}
+
+	logFromCtx(r.Context()).Infof("Shutdown requested via API from %s", clientIP(r))
+	writeJSON(w, r, http.StatusAccepted, map[string]string{
+		"status":  "accepted",
+		"message": "Server is shutting down gracefully",
+	})
+	h.shutdown.fire()
+}
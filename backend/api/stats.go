@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	maxStatsEndpoints  = 256
+	otherStatsEndpoint = "other"
+	statusClassCount   = 5
+)
+
+type RequestStats struct {
+	started   time.Time
+	total     atomic.Int64
+	classes   [statusClassCount]atomic.Int64
+	mu        sync.RWMutex
+	endpoints map[string]*atomic.Int64
+}
+
+type StatsResponse struct {
+	Since     string           `json:"since"`
+	Total     int64            `json:"total"`
+	Status    map[string]int64 `json:"status"`
+	Endpoints map[string]int64 `json:"endpoints"`
+}
+
+func NewRequestStats() *RequestStats {
+	return &RequestStats{
+		started:   time.Now(),
+		endpoints: make(map[string]*atomic.Int64),
+	}
+}
+
+// record bumps the counters while holding s.mu for reading, so a resetting
+// Snapshot, which holds it for writing, never sees a request counted in
+// total but not yet in its endpoint.
+func (s *RequestStats) record(endpoint string, status int) {
+	s.mu.RLock()
+	counter, ok := s.endpoints[endpoint]
+	if ok {
+		s.increment(counter, status)
+		s.mu.RUnlock()
+		return
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.increment(s.endpointCounterLocked(endpoint), status)
+}
+
+func (s *RequestStats) increment(counter *atomic.Int64, status int) {
+	s.total.Add(1)
+	if class := status/100 - 1; class >= 0 && class < statusClassCount {
+		s.classes[class].Add(1)
+	}
+	counter.Add(1)
+}
+
+func (s *RequestStats) endpointCounterLocked(endpoint string) *atomic.Int64 {
+	if counter, ok := s.endpoints[endpoint]; ok {
+		return counter
+	}
+	if len(s.endpoints) >= maxStatsEndpoints {
+		endpoint = otherStatsEndpoint
+		if counter, ok := s.endpoints[endpoint]; ok {
+			return counter
+		}
+	}
+	counter := &atomic.Int64{}
+	s.endpoints[endpoint] = counter
+	return counter
+}
+
+func (s *RequestStats) Snapshot(reset bool) StatsResponse {
+	if reset {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	} else {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	load := func(c *atomic.Int64) int64 {
+		if reset {
+			return c.Swap(0)
+		}
+		return c.Load()
+	}
+
+	resp := StatsResponse{
+		Since:     s.started.UTC().Format(time.RFC3339),
+		Total:     load(&s.total),
+		Status:    make(map[string]int64, statusClassCount),
+		Endpoints: make(map[string]int64, len(s.endpoints)),
+	}
+	for i := range s.classes {
+		resp.Status[strconv.Itoa(i+1)+"xx"] = load(&s.classes[i])
+	}
+	for endpoint, counter := range s.endpoints {
+		resp.Endpoints[endpoint] = load(counter)
+	}
+
+	if reset {
+		s.started = time.Now()
+	}
+	return resp
+}
+
+func StatsMiddleware(next http.Handler, s *RequestStats) http.Handler {
+	if s == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped := wrapResponseWriter(w)
+		next.ServeHTTP(wrapped, r)
+
+		endpoint, _ := routeOperation(r)
+		s.record(endpoint, wrapped.status)
+	})
+}
+
+func StatsMiddlewareFunc(s *RequestStats) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return StatsMiddleware(next, s)
+	}
+}
+
+func (h *Handler) SetRequestStats(s *RequestStats) {
+	h.stats = s
+}
+
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	if h.stats == nil {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, "Request statistics are not enabled")
+		return
+	}
+
+	reset, _ := strconv.ParseBool(r.URL.Query().Get("reset"))
+	writeJSON(w, r, http.StatusOK, h.stats.Snapshot(reset))
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRequestStatsResetIsConsistent(t *testing.T) {
+	stats := NewRequestStats()
+
+	const workers, perWorker = 8, 500
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				stats.record("packages/list", 200)
+			}
+		}()
+	}
+
+	var snapshots []StatsResponse
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		snapshots = append(snapshots, stats.Snapshot(true))
+	}
+
+	var total, success, endpoint int64
+	for _, snap := range snapshots {
+		if snap.Total != snap.Endpoints["packages/list"] || snap.Total != snap.Status["2xx"] {
+			t.Fatalf("inconsistent snapshot: total %d, 2xx %d, endpoint %d",
+				snap.Total, snap.Status["2xx"], snap.Endpoints["packages/list"])
+		}
+		total += snap.Total
+		success += snap.Status["2xx"]
+		endpoint += snap.Endpoints["packages/list"]
+	}
+	if total != workers*perWorker || success != total || endpoint != total {
+		t.Fatalf("counted total %d, 2xx %d, endpoint %d; want %d each", total, success, endpoint, workers*perWorker)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"context"
+	"net/http"
+)
+
+type LogLineEvent struct {
+	Line string `json:"line"`
+}
+
+type StreamResultEvent struct {
+	Status  string `json:"status"`
+	Package string `json:"package"`
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *Handler) InstallPackageStream(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost, http.MethodOptions) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if err := brew.ValidateQualifiedName(name); err != nil {
+		handleBrewError(w, r, err)
+		return
+	}
+
+	release, ok := h.acquireStream(w, r)
+	if !ok {
+		return
+	}
+	defer release()
+
+	stream, ok := startSSE(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeoutFor(r))
+	defer cancel()
+
+	stopKeepAlive := stream.keepAlive(ctx, h.sseKeepAlive)
+
+	var parser brew.ProgressParser
+	err := h.brew.InstallPackageStream(ctx, name, func(line string) {
+		if progress, ok := parser.Parse(line); ok {
+			stream.Send("progress", progress)
+		}
+		stream.Send("log", LogLineEvent{Line: line})
+	})
+	stopKeepAlive()
+
+	result := StreamResultEvent{Status: "success", Package: name}
+	if err != nil {
+		result.Status = "failed"
+		result.Code, result.Error = streamErrorDetails(ctx, err)
+	}
+	reportWebhookOutcome(r.Context(), result.Error)
+	stream.Send("done", result)
+}
+
+func streamErrorDetails(ctx context.Context, err error) (string, string) {
+	_, code, message, _ := classifyBrewError(ctx, err)
+	return code, message
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRequestTimeout = 5 * time.Minute
+	shortRequestTimeout   = 1 * time.Minute
+	longRequestTimeout    = 30 * time.Minute
+	writeDeadlineGrace    = 30 * time.Second
+)
+
+var defaultRouteTimeouts = map[string]time.Duration{
+	"packages":                  shortRequestTimeout,
+	"packages/light":            shortRequestTimeout,
+	"formulae":                  shortRequestTimeout,
+	"casks":                     shortRequestTimeout,
+	"packages/search":           shortRequestTimeout,
+	"packages/usage":            shortRequestTimeout,
+	"packages/describe":         shortRequestTimeout,
+	"packages/popularity":       shortRequestTimeout,
+	"packages/readme":           shortRequestTimeout,
+	"packages/deps":             shortRequestTimeout,
+	"packages/uses":             shortRequestTimeout,
+	"packages/why":              shortRequestTimeout,
+	"services":                  shortRequestTimeout,
+	"services/detail":           shortRequestTimeout,
+	"services/health":           shortRequestTimeout,
+	"system/analytics":          shortRequestTimeout,
+	"packages/install":          longRequestTimeout,
+	"packages/install-stream":   longRequestTimeout,
+	"packages/install-version":  longRequestTimeout,
+	"packages/upgrade":          longRequestTimeout,
+	"packages/upgrade-selected": longRequestTimeout,
+	"packages/reinstall":        longRequestTimeout,
+	"packages/uninstall-batch":  longRequestTimeout,
+	"update":                    longRequestTimeout,
+	"system/update":             longRequestTimeout,
+	"cleanup":                   longRequestTimeout,
+	"system/cleanup":            longRequestTimeout,
+	"doctor/fix":                longRequestTimeout,
+	"import":                    longRequestTimeout,
+}
+
+func (h *Handler) SetRouteTimeouts(overrides map[string]time.Duration) {
+	timeouts := make(map[string]time.Duration, len(defaultRouteTimeouts)+len(overrides))
+	for route, timeout := range defaultRouteTimeouts {
+		timeouts[route] = timeout
+	}
+	for route, timeout := range overrides {
+		if timeout > 0 {
+			timeouts[route] = timeout
+		}
+	}
+	h.routeTimeouts = timeouts
+}
+
+func (h *Handler) SetWriteTimeout(timeout time.Duration) {
+	h.writeTimeout = timeout
+}
+
+func (h *Handler) WriteDeadlineMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if timeout := h.timeoutFor(r); h.writeTimeout > 0 && timeout+writeDeadlineGrace > h.writeTimeout {
+			rc := http.NewResponseController(w)
+			if err := rc.SetWriteDeadline(time.Now().Add(timeout + writeDeadlineGrace)); err != nil && err != http.ErrNotSupported {
+				logFromCtx(r.Context()).Warnf("Failed to extend write deadline: %v", err)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) timeoutFor(r *http.Request) time.Duration {
+	operation, _ := routeOperation(r)
+	if timeout, ok := h.routeTimeouts[operation]; ok {
+		return timeout
+	}
+	return h.requestTimeout
+}
+
+func (h *Handler) routeTimeoutSettings() map[string]string {
+	settings := make(map[string]string, len(h.routeTimeouts))
+	for route, timeout := range h.routeTimeouts {
+		settings[route] = timeout.String()
+	}
+	return settings
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutFor(t *testing.T) {
+	h := NewHandler(nil)
+	h.SetRouteTimeouts(map[string]time.Duration{"packages/search": 5 * time.Second})
+
+	tests := []struct {
+		target string
+		want   time.Duration
+	}{
+		{"/api/packages/search?q=wget", 5 * time.Second},
+		{"/api/packages/describe?name=wget", shortRequestTimeout},
+		{"/api/packages/wget/upgrade", longRequestTimeout},
+		{"/api/system/prefixes", defaultRequestTimeout},
+	}
+
+	for _, tt := range tests {
+		if got := h.timeoutFor(httptest.NewRequest("GET", tt.target, nil)); got != tt.want {
+			t.Errorf("timeoutFor(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"brew-manager/brew"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const maxWebhookErrorBody = 4 * 1024
+
+const webhookOutcomeKey contextKey = "webhook_outcome"
+
+// webhookOutcome lets handlers whose HTTP status does not reflect the result
+// of the operation, such as streamed installs that always answer 200, report
+// how the operation actually ended.
+type webhookOutcome struct {
+	reported bool
+	err      string
+}
+
+func reportWebhookOutcome(ctx context.Context, errMessage string) {
+	if outcome, ok := ctx.Value(webhookOutcomeKey).(*webhookOutcome); ok {
+		outcome.reported = true
+		outcome.err = errMessage
+	}
+}
+
+type webhookResponseWriter struct {
+	*responseWriter
+	body bytes.Buffer
+}
+
+func (w *webhookResponseWriter) Write(b []byte) (int, error) {
+	if w.status >= 400 && w.body.Len() < maxWebhookErrorBody {
+		remaining := maxWebhookErrorBody - w.body.Len()
+		if len(b) < remaining {
+			remaining = len(b)
+		}
+		w.body.Write(b[:remaining])
+	}
+	return w.responseWriter.Write(b)
+}
+
+func (w *webhookResponseWriter) errorMessage() string {
+	if w.status < 400 {
+		return ""
+	}
+
+	var apiErr APIError
+	if err := json.Unmarshal(w.body.Bytes(), &apiErr); err == nil && apiErr.Error != "" {
+		return apiErr.Error
+	}
+	return http.StatusText(w.status)
+}
+
+// rejectedBeforeBrew reports whether status means the request was turned
+// away before any brew command ran, e.g. by validation, read-only mode or
+// the concurrency limiter.
+func rejectedBeforeBrew(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return true
+	default:
+		return false
+	}
+}
+
+func WebhookMiddleware(next http.Handler, b *brew.ServiceManager) http.Handler {
+	if b == nil || !b.WebhookEnabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingOperation(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		outcome := &webhookOutcome{}
+		wrapped := &webhookResponseWriter{responseWriter: wrapResponseWriter(w)}
+		next.ServeHTTP(wrapped, r.WithContext(context.WithValue(r.Context(), webhookOutcomeKey, outcome)))
+
+		if rejectedBeforeBrew(wrapped.status) {
+			return
+		}
+
+		operation, target := auditOperation(r)
+		event := brew.WebhookEvent{
+			Operation:  operation,
+			Target:     target,
+			Status:     "success",
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		switch {
+		case outcome.reported:
+			if outcome.err != "" {
+				event.Status = "failure"
+				event.Error = outcome.err
+			}
+		case wrapped.status == http.StatusMultiStatus:
+			event.Status = "partial"
+		case wrapped.status >= 400:
+			event.Status = "failure"
+			event.Error = wrapped.errorMessage()
+		}
+
+		b.NotifyWebhook(event)
+	})
+}
+
+func WebhookMiddlewareFunc(b *brew.ServiceManager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return WebhookMiddleware(next, b)
+	}
+}
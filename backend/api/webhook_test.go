@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"brew-manager/brew"
+)
+
+func TestWebhookMiddlewareNotifiesMutations(t *testing.T) {
+	events := make(chan brew.WebhookEvent, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event brew.WebhookEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer receiver.Close()
+
+	cfg := brew.DefaultConfig()
+	cfg.WebhookURL = receiver.URL
+	svc := brew.NewService(cfg)
+
+	tests := []struct {
+		name       string
+		target     string
+		handler    http.HandlerFunc
+		wantNotify bool
+		wantStatus string
+		wantError  string
+	}{
+		{
+			name:   "successful install",
+			target: "/api/packages/install?name=wget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			wantNotify: true,
+			wantStatus: "success",
+		},
+		{
+			name:   "partial batch",
+			target: "/api/packages/upgrade-selected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusMultiStatus)
+			},
+			wantNotify: true,
+			wantStatus: "partial",
+		},
+		{
+			name:   "failed install",
+			target: "/api/packages/install?name=wget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "Install failed midway.")
+			},
+			wantNotify: true,
+			wantStatus: "failure",
+			wantError:  "Install failed midway.",
+		},
+		{
+			name:   "failed streamed install",
+			target: "/api/packages/install-stream?name=wget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				reportWebhookOutcome(r.Context(), "brew install failed")
+			},
+			wantNotify: true,
+			wantStatus: "failure",
+			wantError:  "brew install failed",
+		},
+		{
+			name:   "successful streamed install",
+			target: "/api/packages/install-stream?name=wget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				reportWebhookOutcome(r.Context(), "")
+			},
+			wantNotify: true,
+			wantStatus: "success",
+		},
+		{
+			name:   "read-only rejection",
+			target: "/api/packages/install?name=wget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, r, http.StatusForbidden, ErrCodeReadOnly, "read-only")
+			},
+		},
+		{
+			name:   "validation rejection",
+			target: "/api/packages/install?name=-bad",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, r, http.StatusBadRequest, ErrCodeValidation, "invalid name")
+			},
+		},
+		{
+			name:   "rate limited",
+			target: "/api/packages/install?name=wget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, r, http.StatusTooManyRequests, ErrCodeRateLimited, "slow down")
+			},
+		},
+		{
+			name:   "import preview",
+			target: "/api/import",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := WebhookMiddleware(tt.handler, svc)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, tt.target, nil))
+
+			select {
+			case event := <-events:
+				if !tt.wantNotify {
+					t.Fatalf("unexpected webhook %+v", event)
+				}
+				if event.Status != tt.wantStatus || event.Error != tt.wantError {
+					t.Errorf("webhook status %q error %q, want %q %q", event.Status, event.Error, tt.wantStatus, tt.wantError)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantNotify {
+					t.Fatal("expected a webhook notification")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package brew
+
+import (
+	"runtime"
+	"strings"
+)
+
+type caskPackage struct {
+	Package
+	Token            string   `json:"token"`
+	FullToken        string   `json:"full_token"`
+	Names            []string `json:"name"`
+	Version          string   `json:"version"`
+	InstalledVersion *string  `json:"installed"`
+	InstalledTime    int64    `json:"installed_time"`
+	ConflictsWith    struct {
+		Cask []string `json:"cask"`
+	} `json:"conflicts_with"`
+	DependsOn struct {
+		Arch []struct {
+			Type string `json:"type"`
+		} `json:"arch"`
+	} `json:"depends_on"`
+}
+
+func (c caskPackage) toPackage() Package {
+	pkg := c.Package
+	pkg.IsCask = true
+	pkg.Name = c.Token
+	pkg.FullName = c.FullToken
+	if pkg.FullName == "" {
+		pkg.FullName = c.Token
+	}
+	pkg.Versions.Stable = c.Version
+	pkg.ConflictsWith = c.ConflictsWith.Cask
+	if c.InstalledVersion != nil {
+		pkg.Installed = []InstalledKeg{{
+			Version:            *c.InstalledVersion,
+			InstalledOnRequest: true,
+			InstalledTime:      c.InstalledTime,
+		}}
+	}
+
+	var archs []string
+	for _, arch := range c.DependsOn.Arch {
+		if arch.Type != "" {
+			archs = append(archs, arch.Type)
+		}
+	}
+	pkg.ArchRequirement = strings.Join(archs, ",")
+	pkg.RequiresRosetta = requiresRosetta(pkg.ArchRequirement, runtime.GOARCH)
+	return pkg
+}
+
+func requiresRosetta(requirement, hostArch string) bool {
+	if requirement == "" || hostArch != "arm64" {
+		return false
+	}
+	for _, arch := range strings.Split(requirement, ",") {
+		if arch == "arm" {
+			return false
+		}
+	}
+	return true
+}
+
+func HostArch() string {
+	return runtime.GOARCH
+}
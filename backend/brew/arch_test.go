@@ -0,0 +1,93 @@
+package brew
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const intelCaskJSON = `{
+  "formulae": [],
+  "casks": [
+    {
+      "token": "legacy-app",
+      "full_token": "legacy-app",
+      "name": ["Legacy App"],
+      "desc": "Intel-only desktop app",
+      "homepage": "https://example.com/legacy",
+      "version": "2.4.1",
+      "installed": "2.4.0",
+      "installed_time": 1700000000,
+      "outdated": true,
+      "conflicts_with": {"cask": ["legacy-app-beta"]},
+      "depends_on": {"arch": [{"type": "intel", "bits": 64}], "macos": {">=": ["10.15"]}}
+    }
+  ]
+}`
+
+func TestCaskArchRequirement(t *testing.T) {
+	var result brewInfoResponse
+	if err := json.Unmarshal([]byte(intelCaskJSON), &result); err != nil {
+		t.Fatalf("unmarshal cask JSON: %v", err)
+	}
+	if len(result.Casks) != 1 {
+		t.Fatalf("got %d casks, want 1", len(result.Casks))
+	}
+
+	pkg := result.Casks[0].toPackage()
+
+	if !pkg.IsCask {
+		t.Error("IsCask = false, want true")
+	}
+	if pkg.Name != "legacy-app" {
+		t.Errorf("Name = %q, want %q", pkg.Name, "legacy-app")
+	}
+	if pkg.Versions.Stable != "2.4.1" {
+		t.Errorf("Versions.Stable = %q, want %q", pkg.Versions.Stable, "2.4.1")
+	}
+	if len(pkg.Installed) != 1 || pkg.Installed[0].Version != "2.4.0" {
+		t.Errorf("Installed = %+v, want one keg at 2.4.0", pkg.Installed)
+	}
+	if len(pkg.ConflictsWith) != 1 || pkg.ConflictsWith[0] != "legacy-app-beta" {
+		t.Errorf("ConflictsWith = %v, want [legacy-app-beta]", pkg.ConflictsWith)
+	}
+	if pkg.ArchRequirement != "intel" {
+		t.Errorf("ArchRequirement = %q, want %q", pkg.ArchRequirement, "intel")
+	}
+}
+
+func TestCaskWithoutArchRequirement(t *testing.T) {
+	var result brewInfoResponse
+	data := `{"formulae": [], "casks": [{"token": "any-app", "name": ["Any App"], "version": "1.0", "installed": null, "depends_on": {}}]}`
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("unmarshal cask JSON: %v", err)
+	}
+
+	pkg := result.Casks[0].toPackage()
+	if pkg.ArchRequirement != "" || pkg.RequiresRosetta {
+		t.Errorf("ArchRequirement = %q, RequiresRosetta = %v; want none", pkg.ArchRequirement, pkg.RequiresRosetta)
+	}
+	if len(pkg.Installed) != 0 {
+		t.Errorf("Installed = %+v, want empty", pkg.Installed)
+	}
+}
+
+func TestRequiresRosetta(t *testing.T) {
+	tests := []struct {
+		requirement string
+		hostArch    string
+		want        bool
+	}{
+		{"intel", "arm64", true},
+		{"intel", "amd64", false},
+		{"arm", "arm64", false},
+		{"intel,arm", "arm64", false},
+		{"", "arm64", false},
+		{"", "amd64", false},
+	}
+
+	for _, tt := range tests {
+		if got := requiresRosetta(tt.requirement, tt.hostArch); got != tt.want {
+			t.Errorf("requiresRosetta(%q, %q) = %v, want %v", tt.requirement, tt.hostArch, got, tt.want)
+		}
+	}
+}
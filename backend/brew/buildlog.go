@@ -0,0 +1,127 @@
+package brew
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+const maxBuildLogBytes = 64 * 1024
+
+type BuildLog struct {
+	Package   string   `json:"package"`
+	Available bool     `json:"available"`
+	File      string   `json:"file,omitempty"`
+	Files     []string `json:"files,omitempty"`
+	Log       string   `json:"log,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+func (s *ServiceManager) BuildLog(ctx context.Context, name string) (*BuildLog, error) {
+	if err := validatePackageName(name); err != nil {
+		return nil, err
+	}
+
+	root, err := brewLogsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(root, name)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &BuildLog{Package: name, Message: "No build logs found for " + name}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type logFile struct {
+		name    string
+		modTime int64
+	}
+	var files []logFile
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, logFile{name: entry.Name(), modTime: info.ModTime().UnixNano()})
+	}
+	if len(files) == 0 {
+		return &BuildLog{Package: name, Message: "No build logs found for " + name}, nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime > files[j].modTime
+	})
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+
+	tail, truncated, err := tailFile(filepath.Join(dir, files[0].name), maxBuildLogBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BuildLog{
+		Package:   name,
+		Available: true,
+		File:      files[0].name,
+		Files:     names,
+		Log:       tail,
+		Truncated: truncated,
+	}, nil
+}
+
+func brewLogsDir() (string, error) {
+	if dir := os.Getenv("HOMEBREW_LOGS"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Logs", "Homebrew"), nil
+	}
+	return filepath.Join(home, ".cache", "Homebrew", "Logs"), nil
+}
+
+func tailFile(path string, limit int64) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", false, err
+	}
+
+	truncated := info.Size() > limit
+	if truncated {
+		if _, err := f.Seek(-limit, io.SeekEnd); err != nil {
+			return "", false, err
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, limit))
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), truncated, nil
+}
@@ -0,0 +1,69 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+const maxCacheWalkDepth = 6
+
+func (s *ServiceManager) CacheSize(ctx context.Context) (int64, error) {
+	output, err := s.runBrewCommand(ctx, "--cache")
+	if err != nil {
+		return 0, err
+	}
+
+	root := strings.TrimSpace(string(output))
+	if root == "" {
+		return 0, fmt.Errorf("brew --cache returned an empty path")
+	}
+
+	var total int64
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			rel, relErr := filepath.Rel(root, path)
+			if relErr == nil && rel != "." && strings.Count(rel, string(filepath.Separator)) >= maxCacheWalkDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
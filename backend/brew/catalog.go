@@ -0,0 +1,66 @@
+package brew
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const catalogTTL = 6 * time.Hour
+
+type catalogCache struct {
+	mu      sync.Mutex
+	entries map[string]catalogEntry
+}
+
+type catalogEntry struct {
+	names     []string
+	fetchedAt time.Time
+}
+
+func newCatalogCache() *catalogCache {
+	return &catalogCache{entries: make(map[string]catalogEntry)}
+}
+
+func (c *catalogCache) Get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.fetchedAt) > catalogTTL {
+		return nil, false
+	}
+	return entry.names, true
+}
+
+func (c *catalogCache) Put(key string, names []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = catalogEntry{names: names, fetchedAt: time.Now()}
+}
+
+func (s *ServiceManager) AllFormulae(ctx context.Context) ([]string, error) {
+	return s.catalogNames(ctx, "formulae")
+}
+
+func (s *ServiceManager) AllCasks(ctx context.Context) ([]string, error) {
+	return s.catalogNames(ctx, "casks")
+}
+
+func (s *ServiceManager) catalogNames(ctx context.Context, command string) ([]string, error) {
+	key := prefixFromContext(ctx) + "|" + command
+	if names, ok := s.catalog.Get(key); ok {
+		return names, nil
+	}
+
+	output, err := s.runBrewCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	names := strings.Fields(string(output))
+	s.catalog.Put(key, names)
+	return names, nil
+}
@@ -0,0 +1,120 @@
+package brew
+
+import (
+	"brew-manager/logging"
+	"context"
+	"os"
+	"sort"
+)
+
+type prefixKey struct{}
+
+type noAutoUpdateKey struct{}
+
+type packageTypeKey struct{}
+
+type PackageType string
+
+const (
+	PackageTypeFormula PackageType = "formula"
+	PackageTypeCask    PackageType = "cask"
+)
+
+var knownPrefixes = map[string]string{
+	"arm64":  "/opt/homebrew/bin/brew",
+	"x86_64": "/usr/local/bin/brew",
+}
+
+type Prefix struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+func WithPrefix(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, prefixKey{}, name)
+}
+
+func prefixFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(prefixKey{}).(string)
+	return name
+}
+
+func WithNoAutoUpdate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noAutoUpdateKey{}, true)
+}
+
+func noAutoUpdateFromContext(ctx context.Context) bool {
+	noAutoUpdate, _ := ctx.Value(noAutoUpdateKey{}).(bool)
+	return noAutoUpdate
+}
+
+func ParsePackageType(value string) (PackageType, error) {
+	switch t := PackageType(value); t {
+	case "", PackageTypeFormula, PackageTypeCask:
+		return t, nil
+	default:
+		return "", &ValidationError{
+			Field:   "type",
+			Value:   value,
+			Message: "type must be 'formula' or 'cask'",
+		}
+	}
+}
+
+func WithPackageType(ctx context.Context, t PackageType) context.Context {
+	return context.WithValue(ctx, packageTypeKey{}, t)
+}
+
+func packageTypeFromContext(ctx context.Context) PackageType {
+	t, _ := ctx.Value(packageTypeKey{}).(PackageType)
+	return t
+}
+
+func packageTypeArgs(ctx context.Context, args ...string) []string {
+	if t := packageTypeFromContext(ctx); t != "" {
+		return append(args, "--"+string(t))
+	}
+	return args
+}
+
+func DetectPrefixes() map[string]string {
+	detected := make(map[string]string)
+	for name, path := range knownPrefixes {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			detected[name] = path
+		}
+	}
+	return detected
+}
+
+func (s *ServiceManager) Prefixes() []Prefix {
+	prefixes := make([]Prefix, 0, len(s.config.Prefixes))
+	for name, path := range s.config.Prefixes {
+		prefixes = append(prefixes, Prefix{Name: name, Path: path})
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return prefixes[i].Name < prefixes[j].Name
+	})
+	return prefixes
+}
+
+func (s *ServiceManager) brewBinary(ctx context.Context) (string, error) {
+	name := prefixFromContext(ctx)
+	if name == "" {
+		return s.config.BrewPath, nil
+	}
+
+	path, ok := s.config.Prefixes[name]
+	if !ok {
+		return "", &ValidationError{
+			Field:   "prefix",
+			Value:   name,
+			Message: "unknown Homebrew prefix; see /api/system/prefixes for available prefixes",
+		}
+	}
+	return path, nil
+}
+
+func logFromCtx(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx)
+}
@@ -0,0 +1,146 @@
+package brew
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type DoctorDiagnostic struct {
+	Healthy bool          `json:"healthy"`
+	Issues  []DoctorIssue `json:"issues"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type MissingDiagnostic struct {
+	Packages map[string][]string `json:"packages"`
+	Error    string              `json:"error,omitempty"`
+}
+
+type DiagnosticsReport struct {
+	Doctor        DoctorDiagnostic  `json:"doctor"`
+	Missing       MissingDiagnostic `json:"missing"`
+	OutdatedCount *int              `json:"outdated_count"`
+	CacheSize     *int64            `json:"cache_size"`
+	Errors        map[string]string `json:"errors,omitempty"`
+	Score         int               `json:"score"`
+	Overall       string            `json:"overall"`
+}
+
+func (s *ServiceManager) Missing(ctx context.Context) (map[string][]string, error) {
+	output, err := s.runBrewCommand(ctx, "missing")
+	if err != nil {
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) || cmdErr.Stdout == "" {
+			return nil, err
+		}
+		output = []byte(cmdErr.Stdout)
+	}
+	return parseMissingOutput(string(output)), nil
+}
+
+func parseMissingOutput(output string) map[string][]string {
+	missing := make(map[string][]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, deps, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			continue
+		}
+		missing[strings.TrimSpace(name)] = strings.Fields(deps)
+	}
+	return missing
+}
+
+func (s *ServiceManager) Diagnostics(ctx context.Context) *DiagnosticsReport {
+	report := &DiagnosticsReport{
+		Doctor:  DoctorDiagnostic{Issues: []DoctorIssue{}},
+		Missing: MissingDiagnostic{Packages: map[string][]string{}},
+	}
+	sectionErrs := make([]error, 4)
+
+	checks := []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			_, issues, err := s.Doctor(ctx)
+			if err == nil {
+				report.Doctor.Issues = issues
+				report.Doctor.Healthy = len(issues) == 0
+			}
+			return err
+		},
+		func(ctx context.Context) error {
+			missing, err := s.Missing(ctx)
+			if err == nil {
+				report.Missing.Packages = missing
+			}
+			return err
+		},
+		func(ctx context.Context) error {
+			outdated, err := s.Outdated(ctx)
+			if err == nil {
+				count := len(outdated)
+				report.OutdatedCount = &count
+			}
+			return err
+		},
+		func(ctx context.Context) error {
+			size, err := s.CacheSize(ctx)
+			if err == nil {
+				report.CacheSize = &size
+			}
+			return err
+		},
+	}
+
+	s.fanOut(ctx, len(checks), func(ctx context.Context, i int) error {
+		sectionErrs[i] = checks[i](ctx)
+		return nil
+	})
+
+	names := []string{"doctor", "missing", "outdated", "cache_size"}
+	for i, err := range sectionErrs {
+		if err == nil {
+			continue
+		}
+		if report.Errors == nil {
+			report.Errors = make(map[string]string)
+		}
+		report.Errors[names[i]] = diagnosticError(err)
+	}
+	report.Doctor.Error = report.Errors["doctor"]
+	report.Missing.Error = report.Errors["missing"]
+
+	report.Score, report.Overall = scoreDiagnostics(report)
+	return report
+}
+
+func scoreDiagnostics(report *DiagnosticsReport) (int, string) {
+	score := 100
+	score -= 10 * len(report.Doctor.Issues)
+	score -= 15 * len(report.Missing.Packages)
+	if report.OutdatedCount != nil {
+		score -= min(*report.OutdatedCount, 20)
+	}
+	score = max(score, 0)
+
+	switch {
+	case score < 50:
+		return score, "unhealthy"
+	case score < 80 || len(report.Errors) > 0:
+		return score, "degraded"
+	default:
+		return score, "healthy"
+	}
+}
+
+func diagnosticError(err error) string {
+	var timeoutErr *TimeoutError
+	var cmdErr *CommandError
+	switch {
+	case errors.As(err, &timeoutErr):
+		return timeoutErr.Error()
+	case errors.As(err, &cmdErr):
+		return "brew " + cmdErr.Command + " failed"
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,106 @@
+package brew
+
+import (
+	"context"
+	"strings"
+)
+
+type doctorRemedy struct {
+	fixType string
+	pattern string
+	args    []string
+}
+
+var doctorRemedies = []doctorRemedy{
+	{fixType: "broken_symlinks", pattern: "broken symlinks", args: []string{"cleanup"}},
+	{fixType: "outdated_homebrew", pattern: "was last updated", args: []string{"update"}},
+	{fixType: "outdated_homebrew", pattern: "homebrew is outdated", args: []string{"update"}},
+}
+
+type DoctorFix struct {
+	Type    string        `json:"type"`
+	Command string        `json:"command"`
+	Issues  []DoctorIssue `json:"issues"`
+	Output  string        `json:"output,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type DoctorFixResult struct {
+	Fixed   []DoctorFix   `json:"fixed"`
+	Failed  []DoctorFix   `json:"failed"`
+	Unfixed []DoctorIssue `json:"unfixed"`
+}
+
+func FixableDoctorTypes() []string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, remedy := range doctorRemedies {
+		if !seen[remedy.fixType] {
+			seen[remedy.fixType] = true
+			types = append(types, remedy.fixType)
+		}
+	}
+	return types
+}
+
+func matchDoctorRemedy(issue DoctorIssue) (doctorRemedy, bool) {
+	message := strings.ToLower(issue.Message)
+	for _, remedy := range doctorRemedies {
+		if strings.Contains(message, remedy.pattern) {
+			return remedy, true
+		}
+	}
+	return doctorRemedy{}, false
+}
+
+func (s *ServiceManager) FixDoctorIssues(ctx context.Context) (*DoctorFixResult, error) {
+	_, issues, err := s.Doctor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DoctorFixResult{
+		Fixed:   []DoctorFix{},
+		Failed:  []DoctorFix{},
+		Unfixed: []DoctorIssue{},
+	}
+
+	var order []string
+	fixes := make(map[string]*DoctorFix)
+	remedies := make(map[string]doctorRemedy)
+	for _, issue := range issues {
+		remedy, ok := matchDoctorRemedy(issue)
+		if !ok {
+			result.Unfixed = append(result.Unfixed, issue)
+			continue
+		}
+		fix, exists := fixes[remedy.fixType]
+		if !exists {
+			fix = &DoctorFix{
+				Type:    remedy.fixType,
+				Command: "brew " + strings.Join(remedy.args, " "),
+			}
+			fixes[remedy.fixType] = fix
+			remedies[remedy.fixType] = remedy
+			order = append(order, remedy.fixType)
+		}
+		fix.Issues = append(fix.Issues, issue)
+	}
+
+	for _, fixType := range order {
+		fix := fixes[fixType]
+		output, err := s.runExclusive(ctx, remedies[fixType].args...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			fix.Error = err.Error()
+			result.Failed = append(result.Failed, *fix)
+			continue
+		}
+		fix.Output = strings.TrimSpace(string(output))
+		result.Fixed = append(result.Fixed, *fix)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxExecArgs = 16
+
+type execRule struct {
+	flags      map[string]bool
+	positional bool
+}
+
+func flagSet(flags ...string) map[string]bool {
+	set := make(map[string]bool, len(flags))
+	for _, f := range flags {
+		set[f] = true
+	}
+	return set
+}
+
+var execAllowlist = map[string]execRule{
+	"config":       {},
+	"--version":    {},
+	"--cache":      {positional: true},
+	"--prefix":     {positional: true},
+	"--repository": {},
+	"outdated":     {flags: flagSet("--json=v2", "--formula", "--cask", "--greedy", "--verbose"), positional: true},
+	"leaves":       {flags: flagSet("--installed-on-request", "--installed-as-dependency")},
+	"list":         {flags: flagSet("--versions", "--formula", "--cask", "--pinned", "-1"), positional: true},
+	"deps":         {flags: flagSet("--tree", "--installed", "--direct", "--include-build", "--include-optional"), positional: true},
+	"uses":         {flags: flagSet("--installed", "--recursive", "--include-build", "--include-optional"), positional: true},
+	"info":         {flags: flagSet("--json=v2", "--installed", "--formula", "--cask"), positional: true},
+	"search":       {flags: flagSet("--formula", "--cask", "--desc"), positional: true},
+	"desc":         {flags: flagSet("--formula", "--cask"), positional: true},
+	"tap-info":     {flags: flagSet("--installed", "--json")},
+}
+
+type CommandNotAllowedError struct {
+	Command string
+	Arg     string
+}
+
+func (e *CommandNotAllowedError) Error() string {
+	if e.Arg != "" {
+		return fmt.Sprintf("argument %q is not allowed for brew %s", e.Arg, e.Command)
+	}
+	return fmt.Sprintf("brew %s is not an allowed read-only command", e.Command)
+}
+
+func AllowedExecCommands() map[string][]string {
+	commands := make(map[string][]string, len(execAllowlist))
+	for name, rule := range execAllowlist {
+		flags := make([]string, 0, len(rule.flags))
+		for f := range rule.flags {
+			flags = append(flags, f)
+		}
+		sort.Strings(flags)
+		commands[name] = flags
+	}
+	return commands
+}
+
+func (s *ServiceManager) ExecReadOnly(ctx context.Context, command string, args []string) (string, error) {
+	if err := validateExecArgs(command, args); err != nil {
+		return "", err
+	}
+
+	output, err := s.runBrewCommand(ctx, append([]string{command}, args...)...)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// validateExecArgs checks command and args against the allowlist. Positional
+// arguments must be plain names: brew taps user/tap/name arguments on demand,
+// which would break the read-only guarantee.
+func validateExecArgs(command string, args []string) error {
+	rule, ok := execAllowlist[command]
+	if !ok {
+		return &CommandNotAllowedError{Command: command}
+	}
+
+	if len(args) > maxExecArgs {
+		return &ValidationError{
+			Field:   "args",
+			Value:   fmt.Sprintf("%d arguments", len(args)),
+			Message: fmt.Sprintf("at most %d arguments are allowed", maxExecArgs),
+		}
+	}
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			if !rule.flags[arg] {
+				return &CommandNotAllowedError{Command: command, Arg: arg}
+			}
+			continue
+		}
+		if !rule.positional {
+			return &CommandNotAllowedError{Command: command, Arg: arg}
+		}
+		if err := validatePackageName(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package brew
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateExecArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		want    string
+	}{
+		{"allowed command", "config", nil, ""},
+		{"allowed flags and name", "info", []string{"--json=v2", "wget"}, ""},
+		{"versioned name", "deps", []string{"--tree", "python@3.12"}, ""},
+		{"tap-info installed", "tap-info", []string{"--installed", "--json"}, ""},
+		{"unknown command", "install", []string{"wget"}, "not allowed"},
+		{"mutating command", "uninstall", nil, "not allowed"},
+		{"unknown flag", "info", []string{"--eval=1"}, "not allowed"},
+		{"flag from another command", "list", []string{"--tree"}, "not allowed"},
+		{"positional where none allowed", "config", []string{"wget"}, "not allowed"},
+		{"positional for tap-info", "tap-info", []string{"someuser/tap"}, "not allowed"},
+		{"tap-qualified name", "info", []string{"someuser/tap/formula"}, "validation"},
+		{"traversal", "info", []string{"../../etc"}, "validation"},
+		{"shell metacharacters", "desc", []string{"wget;rm"}, "validation"},
+		{"too many args", "info", strings.Fields(strings.Repeat("wget ", maxExecArgs+1)), "validation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateExecArgs(tt.command, tt.args)
+
+			var notAllowedErr *CommandNotAllowedError
+			var validationErr *ValidationError
+			var got string
+			switch {
+			case err == nil:
+			case errors.As(err, &notAllowedErr):
+				got = "not allowed"
+			case errors.As(err, &validationErr):
+				got = "validation"
+			default:
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("validateExecArgs(%q, %q) = %v, want %q", tt.command, tt.args, err, tt.want)
+			}
+		})
+	}
+}
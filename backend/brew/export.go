@@ -0,0 +1,135 @@
+package brew
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+const ExportSchemaVersion = 1
+
+type ExportPackage struct {
+	Name     string   `json:"name"`
+	Versions []string `json:"versions"`
+	IsCask   bool     `json:"is_cask"`
+}
+
+type ExportService struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type ExportDocument struct {
+	SchemaVersion int               `json:"schema_version"`
+	GeneratedAt   string            `json:"generated_at"`
+	Packages      []ExportPackage   `json:"packages"`
+	Pinned        []string          `json:"pinned"`
+	Taps          []string          `json:"taps"`
+	Services      []ExportService   `json:"services"`
+	Incomplete    []string          `json:"incomplete,omitempty"`
+	Errors        map[string]string `json:"errors,omitempty"`
+}
+
+func (s *ServiceManager) ListTaps(ctx context.Context) ([]string, error) {
+	return s.listNames(ctx, "tap")
+}
+
+func (s *ServiceManager) listVersions(ctx context.Context, cask bool) ([]ExportPackage, error) {
+	args := []string{"list", "--formula", "--versions"}
+	if cask {
+		args = []string{"list", "--cask", "--versions"}
+	}
+
+	output, err := s.runBrewCommand(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []ExportPackage
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		packages = append(packages, ExportPackage{
+			Name:     fields[0],
+			Versions: fields[1:],
+			IsCask:   cask,
+		})
+	}
+	return packages, nil
+}
+
+func (s *ServiceManager) Export(ctx context.Context) *ExportDocument {
+	doc := &ExportDocument{
+		SchemaVersion: ExportSchemaVersion,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		Packages:      []ExportPackage{},
+		Pinned:        []string{},
+		Taps:          []string{},
+		Services:      []ExportService{},
+	}
+
+	var formulae, casks []ExportPackage
+	sections := []struct {
+		name string
+		run  func(ctx context.Context) error
+	}{
+		{"formulae", func(ctx context.Context) (err error) {
+			formulae, err = s.listVersions(ctx, false)
+			return err
+		}},
+		{"casks", func(ctx context.Context) (err error) {
+			casks, err = s.listVersions(ctx, true)
+			return err
+		}},
+		{"pinned", func(ctx context.Context) error {
+			pinned, err := s.PinnedPackages(ctx)
+			if err == nil {
+				doc.Pinned = append(doc.Pinned, pinned...)
+			}
+			return err
+		}},
+		{"taps", func(ctx context.Context) error {
+			taps, err := s.ListTaps(ctx)
+			if err == nil {
+				doc.Taps = append(doc.Taps, taps...)
+			}
+			return err
+		}},
+		{"services", func(ctx context.Context) error {
+			services, err := s.ListServices(ctx)
+			if err == nil {
+				for _, svc := range services {
+					doc.Services = append(doc.Services, ExportService{Name: svc.Name, Status: svc.Status})
+				}
+			}
+			return err
+		}},
+	}
+
+	sectionErrs := make([]error, len(sections))
+	s.fanOut(ctx, len(sections), func(ctx context.Context, i int) error {
+		sectionErrs[i] = sections[i].run(ctx)
+		return nil
+	})
+
+	doc.Packages = append(doc.Packages, formulae...)
+	doc.Packages = append(doc.Packages, casks...)
+	sort.Slice(doc.Packages, func(i, j int) bool {
+		return doc.Packages[i].Name < doc.Packages[j].Name
+	})
+
+	for i, err := range sectionErrs {
+		if err == nil {
+			continue
+		}
+		if doc.Errors == nil {
+			doc.Errors = make(map[string]string)
+		}
+		doc.Incomplete = append(doc.Incomplete, sections[i].name)
+		doc.Errors[sections[i].name] = diagnosticError(err)
+	}
+	return doc
+}
@@ -0,0 +1,84 @@
+package brew
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const installedCacheTTL = 30 * time.Second
+
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	val  []Package
+	err  error
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+func (g *flightGroup) Do(ctx context.Context, key string, fn func() ([]Package, error)) ([]Package, error) {
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go func() {
+			call.val, call.err = fn()
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type installedCache struct {
+	mu      sync.Mutex
+	entries map[string]installedEntry
+}
+
+type installedEntry struct {
+	packages  []Package
+	fetchedAt time.Time
+}
+
+func (c *installedCache) Get(key string) ([]Package, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.fetchedAt) > installedCacheTTL {
+		return nil, false
+	}
+	return entry.packages, true
+}
+
+func (c *installedCache) Put(key string, packages []Package) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]installedEntry)
+	}
+	for k, entry := range c.entries {
+		if time.Since(entry.fetchedAt) > installedCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = installedEntry{packages: packages, fetchedAt: time.Now()}
+}
@@ -0,0 +1,104 @@
+package brew
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const installedFixture = `{"formulae":[{"name":"wget","full_name":"wget","installed":[{"version":"1.24.5","installed_on_request":true,"time":1700000000}]}],"casks":[]}`
+
+func TestListInstalledSharesConcurrentCalls(t *testing.T) {
+	s, calls := newFakeBrew(t, "sleep 0.2\necho '"+installedFixture+"'")
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pkgs, err := s.ListInstalled(ctx)
+			if err == nil && (len(pkgs) != 1 || pkgs[0].Name != "wget") {
+				t.Errorf("ListInstalled = %+v, want wget", pkgs)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ListInstalled: %v", err)
+		}
+	}
+
+	if got := len(brewCalls(t, calls)); got != 1 {
+		t.Fatalf("brew ran %d times for concurrent callers, want 1", got)
+	}
+}
+
+func TestListInstalledCacheExpiry(t *testing.T) {
+	s, calls := newFakeBrew(t, "echo '"+installedFixture+"'")
+	ctx := context.Background()
+
+	list := func() {
+		t.Helper()
+		if _, err := s.ListInstalled(ctx); err != nil {
+			t.Fatalf("ListInstalled: %v", err)
+		}
+	}
+
+	list()
+	list()
+	if got := len(brewCalls(t, calls)); got != 1 {
+		t.Fatalf("brew ran %d times within TTL, want 1", got)
+	}
+
+	s.generation.Add(1)
+	list()
+	if got := len(brewCalls(t, calls)); got != 2 {
+		t.Fatalf("brew ran %d times after generation bump, want 2", got)
+	}
+
+	s.installed.mu.Lock()
+	for key, entry := range s.installed.entries {
+		entry.fetchedAt = time.Now().Add(-installedCacheTTL - time.Second)
+		s.installed.entries[key] = entry
+	}
+	s.installed.mu.Unlock()
+	list()
+	if got := len(brewCalls(t, calls)); got != 3 {
+		t.Fatalf("brew ran %d times after TTL expiry, want 3", got)
+	}
+}
+
+func TestInstallPackageStreamInvalidatesInstalledCache(t *testing.T) {
+	s, calls := newFakeBrew(t, `case "$1" in
+info) echo '`+installedFixture+`' ;;
+install) echo '==> Pouring wget--1.24.5.bottle.tar.gz' ;;
+esac`)
+	ctx := context.Background()
+
+	if _, err := s.ListInstalled(ctx); err != nil {
+		t.Fatalf("ListInstalled: %v", err)
+	}
+	if err := s.InstallPackageStream(ctx, "wget", func(string) {}); err != nil {
+		t.Fatalf("InstallPackageStream: %v", err)
+	}
+	if _, err := s.ListInstalled(ctx); err != nil {
+		t.Fatalf("ListInstalled: %v", err)
+	}
+
+	infoCalls := 0
+	for _, call := range brewCalls(t, calls) {
+		if strings.HasPrefix(call, "info") {
+			infoCalls++
+		}
+	}
+	if infoCalls != 2 {
+		t.Fatalf("brew info ran %d times around a streamed install, want 2", infoCalls)
+	}
+}
@@ -0,0 +1,40 @@
+package brew
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFakeBrew returns a ServiceManager whose brew binary is a shell script
+// running body. Every invocation appends its arguments to the returned log
+// file so tests can count how often brew was actually executed.
+func newFakeBrew(t testing.TB, body string) (*ServiceManager, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\n" + body + "\n"
+	path := filepath.Join(dir, "brew")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake brew: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.BrewPath = path
+	return NewService(cfg), calls
+}
+
+func brewCalls(t testing.TB, calls string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(calls)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("read fake brew log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
@@ -0,0 +1,155 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type ImportPlan struct {
+	Taps          []string        `json:"taps"`
+	Install       []ExportPackage `json:"install"`
+	Pin           []string        `json:"pin"`
+	StartServices []string        `json:"start_services"`
+}
+
+func (p *ImportPlan) Empty() bool {
+	return len(p.Taps) == 0 && len(p.Install) == 0 && len(p.Pin) == 0 && len(p.StartServices) == 0
+}
+
+func validateTapName(name string) error {
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 || !packageNameRegex.MatchString(parts[0]) || !packageNameRegex.MatchString(parts[1]) {
+		return &ValidationError{
+			Field:   "taps",
+			Value:   name,
+			Message: "tap name must have the form user/repo",
+		}
+	}
+	return nil
+}
+
+func (s *ServiceManager) AddTap(ctx context.Context, name string) error {
+	if err := validateTapName(name); err != nil {
+		return err
+	}
+
+	_, err := s.runExclusive(ctx, "tap", name)
+	return err
+}
+
+func ValidateImport(doc *ExportDocument) error {
+	if doc.SchemaVersion != ExportSchemaVersion {
+		return &ValidationError{
+			Field:   "schema_version",
+			Value:   fmt.Sprint(doc.SchemaVersion),
+			Message: fmt.Sprintf("unsupported schema_version; expected %d", ExportSchemaVersion),
+		}
+	}
+	for _, tap := range doc.Taps {
+		if err := validateTapName(tap); err != nil {
+			return err
+		}
+	}
+	for _, pkg := range doc.Packages {
+		if err := validateQualifiedName(pkg.Name); err != nil {
+			return err
+		}
+	}
+	for _, name := range doc.Pinned {
+		if err := validatePackageName(name); err != nil {
+			return err
+		}
+	}
+	for _, svc := range doc.Services {
+		if err := validateServiceName(svc.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ServiceManager) PlanImport(ctx context.Context, doc *ExportDocument) (*ImportPlan, error) {
+	if err := ValidateImport(doc); err != nil {
+		return nil, err
+	}
+
+	var formulae, casks []ExportPackage
+	var taps, pinned []string
+	var services []Service
+
+	needServices := false
+	for _, svc := range doc.Services {
+		if svc.Status == "started" {
+			needServices = true
+			break
+		}
+	}
+
+	err := s.fanOut(ctx, 5, func(ctx context.Context, i int) error {
+		var err error
+		switch i {
+		case 0:
+			formulae, err = s.listVersions(ctx, false)
+		case 1:
+			casks, err = s.listVersions(ctx, true)
+		case 2:
+			taps, err = s.ListTaps(ctx)
+		case 3:
+			pinned, err = s.PinnedPackages(ctx)
+		case 4:
+			if needServices {
+				services, err = s.ListServices(ctx)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string]bool, len(formulae)+len(casks))
+	for _, pkg := range append(formulae, casks...) {
+		installed[pkg.Name] = true
+	}
+	tapped := make(map[string]bool, len(taps))
+	for _, tap := range taps {
+		tapped[tap] = true
+	}
+	isPinned := make(map[string]bool, len(pinned))
+	for _, name := range pinned {
+		isPinned[name] = true
+	}
+	started := make(map[string]bool, len(services))
+	for _, svc := range services {
+		started[svc.Name] = svc.Status == "started"
+	}
+
+	plan := &ImportPlan{
+		Taps:          []string{},
+		Install:       []ExportPackage{},
+		Pin:           []string{},
+		StartServices: []string{},
+	}
+	for _, tap := range doc.Taps {
+		if !tapped[tap] {
+			plan.Taps = append(plan.Taps, tap)
+		}
+	}
+	for _, pkg := range doc.Packages {
+		if !installed[pkg.Name] {
+			plan.Install = append(plan.Install, pkg)
+		}
+	}
+	for _, name := range doc.Pinned {
+		if !isPinned[name] {
+			plan.Pin = append(plan.Pin, name)
+		}
+	}
+	for _, svc := range doc.Services {
+		if svc.Status == "started" && !started[svc.Name] {
+			plan.StartServices = append(plan.StartServices, svc.Name)
+		}
+	}
+	return plan, nil
+}
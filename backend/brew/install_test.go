@@ -0,0 +1,57 @@
+package brew
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInstallPackagePartialFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		stderr      string
+		wantPartial bool
+	}{
+		{
+			name:        "bottle extraction",
+			stderr:      "Error: Failure while executing; `tar --extract --no-same-owner --file /Users/me/Library/Caches/Homebrew/downloads/wget.bottle.tar.gz --directory /opt/homebrew/Cellar` exited with 1.",
+			wantPartial: true,
+		},
+		{
+			name:        "source build",
+			stderr:      "Error: wget 1.24.5 did not build\nLogs:\n     /Users/me/Library/Logs/Homebrew/wget/01.configure",
+			wantPartial: true,
+		},
+		{
+			name:        "download",
+			stderr:      "curl: (22) The requested URL returned error: 404\nError: Failure while executing; `/usr/bin/env /opt/homebrew/Library/Homebrew/shims/shared/curl --disable --fail https://ghcr.io/v2/homebrew/core/wget/blobs/sha256:abc` exited with 22.",
+			wantPartial: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, calls := newFakeBrew(t, "case \"$1\" in\ninstall) cat >&2 <<'STDERR'\n"+tt.stderr+"\nSTDERR\nexit 1 ;;\nesac")
+
+			_, err := s.InstallPackage(context.Background(), "wget", true)
+			var partial *PartialInstallError
+			if got := errors.As(err, &partial); got != tt.wantPartial {
+				t.Fatalf("InstallPackage error = %v, partial %v; want partial %v", err, got, tt.wantPartial)
+			}
+
+			cleanedUp := false
+			for _, call := range brewCalls(t, calls) {
+				if strings.HasPrefix(call, "uninstall --force wget") {
+					cleanedUp = true
+				}
+			}
+			if cleanedUp != tt.wantPartial {
+				t.Errorf("cleanup ran = %v, want %v", cleanedUp, tt.wantPartial)
+			}
+			if tt.wantPartial && !partial.CleanedUp {
+				t.Errorf("PartialInstallError.CleanedUp = false after successful cleanup")
+			}
+		})
+	}
+}
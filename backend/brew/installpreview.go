@@ -0,0 +1,45 @@
+package brew
+
+import (
+	"context"
+)
+
+type InstallPreview struct {
+	Package         string   `json:"package"`
+	IsCask          bool     `json:"is_cask"`
+	ArchRequirement string   `json:"arch_requirement,omitempty"`
+	RequiresRosetta bool     `json:"requires_rosetta"`
+	HostArch        string   `json:"host_arch"`
+	NewDependencies []string `json:"new_dependencies"`
+	Count           int      `json:"count"`
+}
+
+func (s *ServiceManager) InstallPreview(ctx context.Context, name string) (*InstallPreview, error) {
+	pkg, err := s.Info(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkg.Installed) > 0 {
+		return nil, &AlreadyInstalledError{Name: name}
+	}
+
+	missing, err := s.cachedList(ctx, append(packageTypeArgs(ctx, "deps", "--missing"), name)...)
+	if err != nil {
+		return nil, classifyPackageError(err, name)
+	}
+	for _, dep := range missing {
+		if err := validateQualifiedName(dep); err != nil {
+			return nil, err
+		}
+	}
+
+	return &InstallPreview{
+		Package:         name,
+		IsCask:          pkg.IsCask,
+		ArchRequirement: pkg.ArchRequirement,
+		RequiresRosetta: pkg.RequiresRosetta,
+		HostArch:        HostArch(),
+		NewDependencies: missing,
+		Count:           len(missing),
+	}, nil
+}
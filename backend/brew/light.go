@@ -0,0 +1,71 @@
+package brew
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+type PackageLight struct {
+	Name     string `json:"name"`
+	Outdated bool   `json:"outdated"`
+	Pinned   bool   `json:"pinned"`
+	IsCask   bool   `json:"is_cask"`
+}
+
+func (s *ServiceManager) ListInstalledLight(ctx context.Context) ([]PackageLight, error) {
+	var formulae, casks, pinned []string
+	var outdated []OutdatedPackage
+
+	err := s.fanOut(ctx, 4, func(ctx context.Context, i int) error {
+		var err error
+		switch i {
+		case 0:
+			formulae, err = s.listNames(ctx, "list", "--formula", "-1")
+		case 1:
+			casks, err = s.listNames(ctx, "list", "--cask", "-1")
+		case 2:
+			pinned, err = s.PinnedPackages(ctx)
+		case 3:
+			outdated, err = s.Outdated(ctx)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	isOutdated := make(map[string]bool, len(outdated))
+	for _, pkg := range outdated {
+		isOutdated[pkg.Name] = true
+	}
+	isPinned := make(map[string]bool, len(pinned))
+	for _, name := range pinned {
+		isPinned[name] = true
+	}
+
+	packages := make([]PackageLight, 0, len(formulae)+len(casks))
+	for _, name := range formulae {
+		packages = append(packages, PackageLight{Name: name, Outdated: isOutdated[name], Pinned: isPinned[name]})
+	}
+	for _, name := range casks {
+		packages = append(packages, PackageLight{Name: name, Outdated: isOutdated[name], IsCask: true})
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+func (s *ServiceManager) PinnedPackages(ctx context.Context) ([]string, error) {
+	return s.listNames(ctx, "list", "--pinned")
+}
+
+func (s *ServiceManager) listNames(ctx context.Context, args ...string) ([]string, error) {
+	output, err := s.runBrewCommand(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
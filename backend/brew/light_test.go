@@ -0,0 +1,115 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const benchInstalledCount = 400
+
+// writeListingFixtures writes canned output for a large install: the full
+// info --json=v2 document plus the plain name and outdated listings used by
+// the light path.
+func writeListingFixtures(b *testing.B, dir string) {
+	b.Helper()
+
+	formulae := make([]map[string]any, 0, benchInstalledCount)
+	names := make([]string, 0, benchInstalledCount)
+	outdated := make([]map[string]any, 0)
+	for i := 0; i < benchInstalledCount; i++ {
+		name := fmt.Sprintf("formula-%03d", i)
+		names = append(names, name)
+		deps := make([]string, 0, 8)
+		for j := 0; j < 8; j++ {
+			deps = append(deps, fmt.Sprintf("formula-%03d", (i+j+1)%benchInstalledCount))
+		}
+		formulae = append(formulae, map[string]any{
+			"name":               name,
+			"full_name":          name,
+			"tap":                "homebrew/core",
+			"desc":               strings.Repeat("A realistic description of the formula. ", 4),
+			"homepage":           "https://example.com/" + name,
+			"license":            "MIT",
+			"versions":           map[string]any{"stable": "1.2.3", "head": "HEAD", "bottle": true},
+			"urls":               map[string]any{"stable": map[string]any{"url": "https://example.com/" + name + ".tar.gz", "checksum": strings.Repeat("ab", 32)}},
+			"dependencies":       deps,
+			"build_dependencies": []string{"pkgconf", "cmake"},
+			"caveats":            strings.Repeat("Caveat text line.\n", 6),
+			"installed":          []map[string]any{{"version": "1.2.2", "installed_on_request": i%3 == 0, "installed_as_dependency": i%3 != 0, "time": 1700000000}},
+			"outdated":           i%10 == 0,
+			"pinned":             false,
+			"deprecated":         false,
+			"disabled":           false,
+		})
+		if i%10 == 0 {
+			outdated = append(outdated, map[string]any{
+				"name":               name,
+				"installed_versions": []string{"1.2.2"},
+				"current_version":    "1.2.3",
+				"pinned":             false,
+			})
+		}
+	}
+
+	files := map[string]any{
+		"info.json":     map[string]any{"formulae": formulae, "casks": []any{}},
+		"outdated.json": map[string]any{"formulae": outdated, "casks": []any{}},
+	}
+	for file, doc := range files {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			b.Fatalf("marshal %s: %v", file, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0o644); err != nil {
+			b.Fatalf("write %s: %v", file, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "formulae.txt"), []byte(strings.Join(names, "\n")+"\n"), 0o644); err != nil {
+		b.Fatalf("write formulae.txt: %v", err)
+	}
+}
+
+func newListingBench(b *testing.B) *ServiceManager {
+	b.Helper()
+
+	dir := b.TempDir()
+	writeListingFixtures(b, dir)
+	s, _ := newFakeBrew(b, fmt.Sprintf(`case "$1 $2" in
+"info --installed") cat %[1]s/info.json ;;
+"list --formula") cat %[1]s/formulae.txt ;;
+"outdated --json=v2") cat %[1]s/outdated.json ;;
+esac`, dir))
+	return s
+}
+
+func BenchmarkListInstalledFull(b *testing.B) {
+	s := newListingBench(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.generation.Add(1)
+		pkgs, err := s.ListInstalled(ctx)
+		if err != nil || len(pkgs) != benchInstalledCount {
+			b.Fatalf("ListInstalled = %d packages, %v", len(pkgs), err)
+		}
+	}
+}
+
+func BenchmarkListInstalledLight(b *testing.B) {
+	s := newListingBench(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pkgs, err := s.ListInstalledLight(ctx)
+		if err != nil || len(pkgs) != benchInstalledCount {
+			b.Fatalf("ListInstalledLight = %d packages, %v", len(pkgs), err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package brew
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Operation struct {
+	ID         uint64     `json:"id"`
+	Type       string     `json:"type"`
+	Target     string     `json:"target,omitempty"`
+	EnqueuedAt time.Time  `json:"enqueued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+}
+
+type OperationsSnapshot struct {
+	Running *Operation  `json:"running"`
+	Queued  []Operation `json:"queued"`
+}
+
+type operationTracker struct {
+	mu      sync.Mutex
+	nextID  uint64
+	running *Operation
+	queued  map[uint64]*Operation
+}
+
+func newOperationTracker() *operationTracker {
+	return &operationTracker{queued: make(map[uint64]*Operation)}
+}
+
+func (t *operationTracker) enqueue(opType, target string) *Operation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	op := &Operation{
+		ID:         t.nextID,
+		Type:       opType,
+		Target:     target,
+		EnqueuedAt: time.Now().UTC(),
+	}
+	t.queued[op.ID] = op
+	return op
+}
+
+func (t *operationTracker) start(op *Operation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	op.StartedAt = &now
+	delete(t.queued, op.ID)
+	t.running = op
+}
+
+func (t *operationTracker) done(op *Operation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.queued, op.ID)
+	if t.running == op {
+		t.running = nil
+	}
+}
+
+func (t *operationTracker) snapshot() OperationsSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snap := OperationsSnapshot{Queued: make([]Operation, 0, len(t.queued))}
+	if t.running != nil {
+		running := *t.running
+		snap.Running = &running
+	}
+	for _, op := range t.queued {
+		snap.Queued = append(snap.Queued, *op)
+	}
+	sort.Slice(snap.Queued, func(i, j int) bool {
+		return snap.Queued[i].ID < snap.Queued[j].ID
+	})
+	return snap
+}
+
+func (s *ServiceManager) Operations() OperationsSnapshot {
+	return s.operations.snapshot()
+}
+
+func (s *ServiceManager) WithOperation(ctx context.Context, opType, target string, fn func() error) error {
+	op := s.operations.enqueue(opType, target)
+	defer s.operations.done(op)
+
+	select {
+	case s.lock <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.lock }()
+
+	s.operations.start(op)
+	return fn()
+}
+
+func operationFromArgs(args []string) (string, string) {
+	if len(args) == 0 {
+		return "", ""
+	}
+
+	var targets []string
+	for _, arg := range args[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			targets = append(targets, arg)
+		}
+	}
+	return args[0], strings.Join(targets, " ")
+}
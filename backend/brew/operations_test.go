@@ -0,0 +1,25 @@
+package brew
+
+import "testing"
+
+func TestOperationFromArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantType   string
+		wantTarget string
+	}{
+		{nil, "", ""},
+		{[]string{"update"}, "update", ""},
+		{[]string{"install", "wget"}, "install", "wget"},
+		{[]string{"install", "--cask", "firefox"}, "install", "firefox"},
+		{[]string{"upgrade", "wget", "curl", "--formula"}, "upgrade", "wget curl"},
+		{[]string{"uninstall", "--force", "a", "--zap", "b"}, "uninstall", "a b"},
+	}
+
+	for _, tt := range tests {
+		gotType, gotTarget := operationFromArgs(tt.args)
+		if gotType != tt.wantType || gotTarget != tt.wantTarget {
+			t.Errorf("operationFromArgs(%q) = %q, %q; want %q, %q", tt.args, gotType, gotTarget, tt.wantType, tt.wantTarget)
+		}
+	}
+}
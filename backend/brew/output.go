@@ -0,0 +1,45 @@
+package brew
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const maxStderrBytes = 1024
+
+type OutputTooLargeError struct {
+	Command string
+	Limit   int64
+}
+
+func (e *OutputTooLargeError) Error() string {
+	return fmt.Sprintf("brew %s produced more than %d bytes of output", e.Command, e.Limit)
+}
+
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	exceeded bool
+	onExceed func()
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.exceeded {
+		return len(p), nil
+	}
+
+	remaining := b.limit - int64(b.buf.Len())
+	if int64(len(p)) > remaining {
+		b.buf.Write(p[:remaining])
+		b.exceeded = true
+		if b.onExceed != nil {
+			b.onExceed()
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
@@ -0,0 +1,50 @@
+package brew
+
+import (
+	"context"
+	"sync"
+)
+
+func (s *ServiceManager) fanOut(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	errs := make(chan error, 1)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.config.MaxConcurrentReads, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(ctx, i); err != nil {
+					select {
+					case errs <- err:
+						cancel()
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package brew
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	PhaseDownload = "download"
+	PhasePour     = "pour"
+	PhaseLink     = "link"
+	PhaseBuild    = "build"
+	PhaseCaveats  = "caveats"
+	PhaseSummary  = "summary"
+)
+
+var progressPercentRegex = regexp.MustCompile(`#+\s*(\d{1,3}(?:\.\d+)?)%`)
+
+type ProgressEvent struct {
+	Phase   string  `json:"phase"`
+	Percent float64 `json:"percent"`
+	Target  string  `json:"target,omitempty"`
+}
+
+type ProgressParser struct {
+	phase  string
+	target string
+}
+
+func (p *ProgressParser) Parse(line string) (*ProgressEvent, bool) {
+	trimmed := strings.TrimSpace(line)
+
+	if header, ok := strings.CutPrefix(trimmed, "==> "); ok {
+		phase := phaseForHeader(header)
+		if phase == "" {
+			return nil, false
+		}
+		p.phase = phase
+		p.target = strings.TrimSpace(header[strings.IndexByte(header, ' ')+1:])
+
+		percent := 0.0
+		if phase == PhaseSummary {
+			percent = 100
+		}
+		return &ProgressEvent{Phase: p.phase, Percent: percent, Target: p.target}, true
+	}
+
+	match := progressPercentRegex.FindStringSubmatch(trimmed)
+	if match == nil {
+		return nil, false
+	}
+
+	percent, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || percent > 100 {
+		return nil, false
+	}
+
+	phase := p.phase
+	if phase == "" {
+		phase = PhaseDownload
+	}
+	return &ProgressEvent{Phase: phase, Percent: percent, Target: p.target}, true
+}
+
+func phaseForHeader(header string) string {
+	switch {
+	case strings.HasPrefix(header, "Downloading"), strings.HasPrefix(header, "Fetching"):
+		return PhaseDownload
+	case strings.HasPrefix(header, "Pouring"):
+		return PhasePour
+	case strings.HasPrefix(header, "Linking"):
+		return PhaseLink
+	case strings.HasPrefix(header, "Installing"), strings.HasPrefix(header, "./configure"),
+		strings.HasPrefix(header, "make"), strings.HasPrefix(header, "cmake"):
+		return PhaseBuild
+	case strings.HasPrefix(header, "Caveats"):
+		return PhaseCaveats
+	case strings.HasPrefix(header, "Summary"):
+		return PhaseSummary
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,95 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const maxReadmeBytes = 256 * 1024
+
+var githubSegmentRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+type Readme struct {
+	Available bool   `json:"available"`
+	Source    string `json:"source,omitempty"`
+	Markdown  string `json:"markdown,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+func (s *ServiceManager) Readme(ctx context.Context, name string) (*Readme, error) {
+	pkg, err := s.Info(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, repo, ok := parseGitHubRepo(pkg.Homepage)
+	if !ok {
+		return &Readme{Message: "No README available; the homepage is not a GitHub repository."}, nil
+	}
+
+	markdown, err := s.fetchGitHubReadme(ctx, owner, repo)
+	if err != nil {
+		return &Readme{Message: fmt.Sprintf("No README available for %s/%s.", owner, repo)}, nil
+	}
+
+	return &Readme{
+		Available: true,
+		Source:    fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+		Markdown:  markdown,
+	}, nil
+}
+
+func parseGitHubRepo(homepage string) (string, string, bool) {
+	u, err := url.Parse(homepage)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", "", false
+	}
+	if host := strings.ToLower(u.Host); host != "github.com" && host != "www.github.com" {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return "", "", false
+	}
+
+	owner, repo := parts[0], strings.TrimSuffix(parts[1], ".git")
+	if !githubSegmentRegex.MatchString(owner) || !githubSegmentRegex.MatchString(repo) {
+		return "", "", false
+	}
+	return owner, repo, true
+}
+
+func (s *ServiceManager) fetchGitHubReadme(ctx context.Context, owner, repo string) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/readme", owner, repo)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.raw")
+	req.Header.Set("User-Agent", "brew-manager")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReadmeBytes))
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
@@ -0,0 +1,59 @@
+package brew
+
+import (
+	"context"
+	"strings"
+)
+
+type RemovableCandidate struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+type Removable struct {
+	Candidates []RemovableCandidate `json:"candidates"`
+	TotalSize  int64                `json:"total_size"`
+}
+
+func (s *ServiceManager) Removable(ctx context.Context) (*Removable, error) {
+	output, err := s.runBrewCommand(ctx, "autoremove", "--dry-run")
+	if err != nil {
+		return nil, err
+	}
+
+	names := parseAutoremoveOutput(string(output))
+	result := &Removable{Candidates: make([]RemovableCandidate, 0, len(names))}
+	if len(names) == 0 {
+		return result, nil
+	}
+
+	pkgs, err := s.infoMany(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64, len(pkgs))
+	for _, pkg := range pkgs {
+		sizes[pkg.Name] = pkg.InstalledSize
+	}
+
+	for _, name := range names {
+		result.Candidates = append(result.Candidates, RemovableCandidate{Name: name, Size: sizes[name]})
+		result.TotalSize += sizes[name]
+	}
+	return result, nil
+}
+
+func parseAutoremoveOutput(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "==>") {
+			continue
+		}
+		if packageNameRegex.MatchString(line) {
+			names = append(names, line)
+		}
+	}
+	return names
+}
@@ -0,0 +1,109 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var brewVersionRegex = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+func (s *ServiceManager) SelfUpdateAvailable(ctx context.Context) (bool, string, error) {
+	current, err := s.Version(ctx)
+	if err != nil {
+		return false, "", err
+	}
+
+	installed := brewVersionRegex.FindString(current)
+	if installed == "" {
+		return false, "", fmt.Errorf("could not parse Homebrew version from %q", current)
+	}
+
+	repo, err := s.runBrewCommand(ctx, "--repository")
+	if err != nil {
+		return false, "", err
+	}
+
+	latest, err := s.latestBrewRelease(ctx, strings.TrimSpace(string(repo)))
+	if err != nil {
+		return false, "", err
+	}
+
+	if compareVersions(latest, installed) > 0 {
+		return true, fmt.Sprintf("Homebrew %s is available (installed: %s)", latest, installed), nil
+	}
+	return false, fmt.Sprintf("Homebrew %s is up to date", installed), nil
+}
+
+func (s *ServiceManager) latestBrewRelease(ctx context.Context, repo string) (string, error) {
+	timeout := s.config.CommandTimeouts[CategoryRead]
+	if timeout == 0 {
+		timeout = s.config.CommandTimeout
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := []string{"-C", repo, "ls-remote", "--tags", "--refs", "origin"}
+	logFromCtx(ctx).Debugf("Running git %s", strings.Join(args, " "))
+
+	cmd := exec.CommandContext(cmdCtx, "git", args...)
+	cmd.Env = s.commandEnv(ctx)
+	output, err := cmd.Output()
+	if err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			return "", &TimeoutError{Command: "git ls-remote", Timeout: timeout}
+		}
+		stderr := ""
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = string(exitErr.Stderr)
+		}
+		return "", &CommandError{Command: "git", Args: args, Stderr: stderr, Cause: err}
+	}
+
+	latest := parseLatestTag(string(output))
+	if latest == "" {
+		return "", fmt.Errorf("no release tags found in %s", repo)
+	}
+	return latest, nil
+}
+
+func parseLatestTag(output string) string {
+	latest := ""
+	for _, line := range strings.Split(output, "\n") {
+		_, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		tag, ok := strings.CutPrefix(ref, "refs/tags/")
+		if !ok || brewVersionRegex.FindString(tag) != tag {
+			continue
+		}
+		if latest == "" || compareVersions(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+	return latest
+}
+
+func compareVersions(a, b string) int {
+	pa := brewVersionRegex.FindStringSubmatch(a)
+	pb := brewVersionRegex.FindStringSubmatch(b)
+	if pa == nil || pb == nil {
+		return strings.Compare(a, b)
+	}
+
+	for i := 1; i < len(pa); i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			if na > nb {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
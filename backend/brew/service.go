@@ -1,29 +1,84 @@
 package brew
 
 import (
+	"brew-manager/logging"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
 	"os/exec"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode"
+)
+
+type CommandCategory string
+
+const (
+	CategoryRead   CommandCategory = "read"
+	CategoryMutate CommandCategory = "mutate"
 )
 
 type Config struct {
 
 	CommandTimeout time.Duration
 
+	CommandTimeouts map[CommandCategory]time.Duration
+
 	HTTPTimeout time.Duration
+
+	DisableCheatSheet bool
+
+	BrewPath string
+
+	Prefixes map[string]string
+
+	ProxyURL string
+
+	ServicePorts map[string]int
+
+	MaxConcurrentReads int
+
+	CheatSheetUserAgent string
+
+	CheatSheetAccept string
+
+	WebhookURL string
+
+	WebhookSecret string
+
+	MaxOutputBytes int64
 }
 
 func DefaultConfig() Config {
 	return Config{
-		CommandTimeout: 5 * time.Minute,
-		HTTPTimeout:    10 * time.Second,
+		CommandTimeout:      5 * time.Minute,
+		HTTPTimeout:         10 * time.Second,
+		BrewPath:            "brew",
+		MaxConcurrentReads:  4,
+		CheatSheetUserAgent: "curl/7.64.1",
+		MaxOutputBytes:      16 * 1024 * 1024,
+		CommandTimeouts: map[CommandCategory]time.Duration{
+			CategoryRead:   1 * time.Minute,
+			CategoryMutate: 30 * time.Minute,
+		},
+		ServicePorts: map[string]int{
+			"postgresql":        5432,
+			"mysql":             3306,
+			"mariadb":           3306,
+			"redis":             6379,
+			"nginx":             8080,
+			"memcached":         11211,
+			"mongodb-community": 27017,
+		},
 	}
 }
 
@@ -47,6 +102,8 @@ type CommandError struct {
 
 	Stderr  string   
 
+	Stdout  string
+
 	Cause   error    
 
 }
@@ -59,6 +116,30 @@ func (e *CommandError) Unwrap() error {
 	return e.Cause
 }
 
+type BrewLockedError struct {
+	Command string
+}
+
+func (e *BrewLockedError) Error() string {
+	return fmt.Sprintf("brew %s failed: another Homebrew process is already in progress", e.Command)
+}
+
+// brewLockedMessages are the stderr fragments brew prints when another
+// process holds its update or keg lock.
+var brewLockedMessages = []string{
+	"Another active Homebrew",
+	"has already locked",
+}
+
+func isBrewLocked(stderr string) bool {
+	for _, msg := range brewLockedMessages {
+		if strings.Contains(stderr, msg) {
+			return true
+		}
+	}
+	return false
+}
+
 type TimeoutError struct {
 	Command string        
 
@@ -70,6 +151,71 @@ func (e *TimeoutError) Error() string {
 	return fmt.Sprintf("brew %s timed out after %v", e.Command, e.Timeout)
 }
 
+type VersionNotAvailableError struct {
+	Name    string
+
+	Version string
+}
+
+func (e *VersionNotAvailableError) Error() string {
+	return fmt.Sprintf("version %s of %s is not available in any tap", e.Version, e.Name)
+}
+
+type AlreadyInstalledError struct {
+	Name string
+}
+
+func (e *AlreadyInstalledError) Error() string {
+	return fmt.Sprintf("%s is already installed", e.Name)
+}
+
+type NotInstalledError struct {
+	Name string
+}
+
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("%s is not installed", e.Name)
+}
+
+type PackageNotFoundError struct {
+	Name string
+}
+
+func (e *PackageNotFoundError) Error() string {
+	return fmt.Sprintf("no formula or cask named %s was found", e.Name)
+}
+
+var notFoundMessages = []string{
+	"No available formula",
+	"No casks found",
+	"No formulae or casks found",
+	"No Cask with this name exists",
+}
+
+func classifyPackageError(err error, name string) error {
+	switch {
+	case stderrContains(err, notFoundMessages...):
+		return &PackageNotFoundError{Name: name}
+	case stderrContains(err, "No such keg", "not installed"):
+		return &NotInstalledError{Name: name}
+	default:
+		return err
+	}
+}
+
+func stderrContains(err error, substrings ...string) bool {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	for _, sub := range substrings {
+		if strings.Contains(cmdErr.Stderr, sub) {
+			return true
+		}
+	}
+	return false
+}
+
 var packageNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9@._+-]*$`)
 
 const maxPackageNameLength = 128
@@ -103,6 +249,117 @@ func validatePackageName(name string) error {
 	return nil
 }
 
+func validateQualifiedName(name string) error {
+	if !strings.Contains(name, "/") {
+		return validatePackageName(name)
+	}
+
+	if len(name) > maxPackageNameLength {
+		return &ValidationError{
+			Field:   "name",
+			Value:   name[:20] + "...",
+			Message: fmt.Sprintf("package name exceeds maximum length of %d", maxPackageNameLength),
+		}
+	}
+
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 {
+		return &ValidationError{
+			Field:   "name",
+			Value:   name,
+			Message: "qualified package name must have the form user/tap/name",
+		}
+	}
+
+	for _, part := range parts {
+		if !packageNameRegex.MatchString(part) {
+			return &ValidationError{
+				Field:   "name",
+				Value:   name,
+				Message: "qualified package name segment contains invalid characters; each must match pattern: " + packageNameRegex.String(),
+			}
+		}
+	}
+
+	return nil
+}
+
+var serviceNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9@._+-]*$`)
+
+const maxServiceNameLength = 128
+
+func validateServiceName(name string) error {
+	if name == "" {
+		return &ValidationError{
+			Field:   "name",
+			Value:   "",
+			Message: "service name is required",
+		}
+	}
+
+	if len(name) > maxServiceNameLength {
+		return &ValidationError{
+			Field:   "name",
+			Value:   name[:20] + "...",
+			Message: fmt.Sprintf("service name exceeds maximum length of %d", maxServiceNameLength),
+		}
+	}
+
+	if !serviceNameRegex.MatchString(name) {
+		return &ValidationError{
+			Field:   "name",
+			Value:   name,
+			Message: "service name contains invalid characters; must match pattern: " + serviceNameRegex.String(),
+		}
+	}
+
+	return nil
+}
+
+var versionRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._+-]*$`)
+
+const maxVersionLength = 64
+
+func validateVersion(version string) error {
+	if version == "" {
+		return &ValidationError{
+			Field:   "version",
+			Value:   "",
+			Message: "version is required",
+		}
+	}
+
+	if len(version) > maxVersionLength {
+		return &ValidationError{
+			Field:   "version",
+			Value:   version[:20] + "...",
+			Message: fmt.Sprintf("version exceeds maximum length of %d", maxVersionLength),
+		}
+	}
+
+	if !versionRegex.MatchString(version) {
+		return &ValidationError{
+			Field:   "version",
+			Value:   version,
+			Message: "version contains invalid characters; must match pattern: " + versionRegex.String(),
+		}
+	}
+
+	return nil
+}
+
+func ValidateVersion(version string) error {
+	return validateVersion(version)
+}
+
+func ValidatePackageName(name string) error {
+	return validatePackageName(name)
+}
+
+func ValidateQualifiedName(name string) error {
+	return validateQualifiedName(name)
+}
+
 func validateServiceAction(action string) error {
 	switch action {
 	case "start", "stop", "restart":
@@ -124,15 +381,14 @@ type Package struct {
 	Versions struct {
 		Stable string `json:"stable"`
 	} `json:"versions"`
-	Installed []struct {
-		Version               string `json:"version"`
-		InstalledOnRequest    bool   `json:"installed_on_request"`
-		InstalledAsDependency bool   `json:"installed_as_dependency"`
-		InstalledTime         int64  `json:"time,omitempty"` 
+	Installed []InstalledKeg `json:"installed"`
 
-	} `json:"installed"`
 	Outdated          bool     `json:"outdated"`
 	Pinned            bool     `json:"pinned"`
+	Deprecated        bool     `json:"deprecated"`
+	DeprecationReason string   `json:"deprecation_reason,omitempty"`
+	Disabled          bool     `json:"disabled"`
+	DisableReason     string   `json:"disable_reason,omitempty"`
 	Dependencies      []string `json:"dependencies"`
 	BuildDependencies []string `json:"build_dependencies"`
 	Caveats           string   `json:"caveats"`
@@ -144,6 +400,15 @@ type Package struct {
 
 	IsCask        bool   `json:"is_cask"`                  
 
+	ArchRequirement string `json:"arch_requirement,omitempty"`
+	RequiresRosetta bool   `json:"requires_rosetta"`
+}
+
+type InstalledKeg struct {
+	Version               string `json:"version"`
+	InstalledOnRequest    bool   `json:"installed_on_request"`
+	InstalledAsDependency bool   `json:"installed_as_dependency"`
+	InstalledTime         int64  `json:"time,omitempty"`
 }
 
 type Service struct {
@@ -154,6 +419,8 @@ type Service struct {
 	Running  bool   `json:"running"` 
 
 	Homepage string `json:"homepage"`
+	PID      int    `json:"pid,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
 }
 
 type serviceListEntry struct {
@@ -165,34 +432,129 @@ type serviceListEntry struct {
 }
 
 type brewInfoResponse struct {
-	Formulae []Package `json:"formulae"`
-	Casks    []Package `json:"casks"`
+	Formulae []Package     `json:"formulae"`
+	Casks    []caskPackage `json:"casks"`
 }
 
 type ServiceManager struct {
 	config     Config
 	httpClient *http.Client
+	lock       chan struct{}
+	generation atomic.Uint64
+	depsCache  *lruCache
+	catalog    *catalogCache
+
+	installed       installedCache
+	installedFlight *flightGroup
+	operations      *operationTracker
 }
 
 func NewService(cfg Config) *ServiceManager {
 
-	if cfg.CommandTimeout == 0 {
+	if cfg.CommandTimeout <= 0 {
 		cfg.CommandTimeout = DefaultConfig().CommandTimeout
 	}
+	timeouts := make(map[CommandCategory]time.Duration)
+	for category, timeout := range DefaultConfig().CommandTimeouts {
+		timeouts[category] = timeout
+	}
+	for category, timeout := range cfg.CommandTimeouts {
+		if timeout > 0 {
+			timeouts[category] = timeout
+		}
+	}
+	cfg.CommandTimeouts = timeouts
+
+	ports := make(map[string]int)
+	for name, port := range DefaultConfig().ServicePorts {
+		ports[name] = port
+	}
+	for name, port := range cfg.ServicePorts {
+		ports[name] = port
+	}
+	cfg.ServicePorts = ports
 	if cfg.HTTPTimeout == 0 {
 		cfg.HTTPTimeout = DefaultConfig().HTTPTimeout
 	}
+	if cfg.BrewPath == "" {
+		cfg.BrewPath = DefaultConfig().BrewPath
+	}
+	if cfg.MaxConcurrentReads <= 0 {
+		cfg.MaxConcurrentReads = DefaultConfig().MaxConcurrentReads
+	}
+	if cfg.CheatSheetUserAgent == "" {
+		cfg.CheatSheetUserAgent = DefaultConfig().CheatSheetUserAgent
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = DefaultConfig().MaxOutputBytes
+	}
+
+	httpClient := &http.Client{
+		Timeout: cfg.HTTPTimeout,
+	}
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			logging.Warnf("Ignoring invalid proxy URL %q", cfg.ProxyURL)
+			cfg.ProxyURL = ""
+		} else {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.Proxy = http.ProxyURL(proxyURL)
+			httpClient.Transport = transport
+		}
+	}
 
 	return &ServiceManager{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: cfg.HTTPTimeout,
-		},
+		config:     cfg,
+		httpClient: httpClient,
+		lock:       make(chan struct{}, 1),
+		depsCache:  newLRUCache(defaultDepsCacheSize),
+		catalog:    newCatalogCache(),
+
+		installedFlight: newFlightGroup(),
+		operations:      newOperationTracker(),
 	}
 }
 
+func (s *ServiceManager) Config() Config {
+	cfg := s.config
+
+	cfg.CommandTimeouts = make(map[CommandCategory]time.Duration, len(s.config.CommandTimeouts))
+	for category, timeout := range s.config.CommandTimeouts {
+		cfg.CommandTimeouts[category] = timeout
+	}
+	cfg.ServicePorts = make(map[string]int, len(s.config.ServicePorts))
+	for name, port := range s.config.ServicePorts {
+		cfg.ServicePorts[name] = port
+	}
+	cfg.Prefixes = make(map[string]string, len(s.config.Prefixes))
+	for name, path := range s.config.Prefixes {
+		cfg.Prefixes[name] = path
+	}
+	return cfg
+}
+
 func (s *ServiceManager) ListInstalled(ctx context.Context) ([]Package, error) {
-	output, err := s.runBrewCommand(ctx, "info", "--installed", "--json=v2")
+	key := fmt.Sprintf("%d|%s|%s", s.generation.Load(), prefixFromContext(ctx), packageTypeFromContext(ctx))
+	if cached, ok := s.installed.Get(key); ok {
+		return append([]Package(nil), cached...), nil
+	}
+
+	packages, err := s.installedFlight.Do(ctx, key, func() ([]Package, error) {
+		packages, err := s.listInstalled(context.WithoutCancel(ctx))
+		if err == nil {
+			s.installed.Put(key, packages)
+		}
+		return packages, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]Package(nil), packages...), nil
+}
+
+func (s *ServiceManager) listInstalled(ctx context.Context) ([]Package, error) {
+	output, err := s.runBrewCommand(ctx, packageTypeArgs(ctx, "info", "--installed", "--json=v2")...)
 	if err != nil {
 		return nil, err
 	}
@@ -213,165 +575,720 @@ func (s *ServiceManager) ListInstalled(ctx context.Context) ([]Package, error) {
 		packages = append(packages, pkg)
 	}
 
-	for _, pkg := range result.Casks {
-		pkg.IsCask = true
+	for _, cask := range result.Casks {
+		pkg := cask.toPackage()
 
 		if len(pkg.Installed) > 0 && pkg.Installed[0].InstalledTime > 0 {
 			pkg.InstallDate = time.Unix(pkg.Installed[0].InstalledTime, 0).Format(time.RFC3339)
 		}
 		packages = append(packages, pkg)
 	}
-
-	return packages, nil
+
+	return packages, nil
+}
+
+type OutdatedPackage struct {
+	Name              string   `json:"name"`
+	InstalledVersions []string `json:"installed_versions"`
+	CurrentVersion    string   `json:"current_version"`
+	Pinned            bool     `json:"pinned"`
+	PinnedVersion     string   `json:"pinned_version,omitempty"`
+	IsCask            bool     `json:"is_cask"`
+}
+
+type brewOutdatedResponse struct {
+	Formulae []OutdatedPackage `json:"formulae"`
+	Casks    []OutdatedPackage `json:"casks"`
+}
+
+func (s *ServiceManager) Outdated(ctx context.Context) ([]OutdatedPackage, error) {
+	output, err := s.runBrewCommand(ctx, "outdated", "--json=v2")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOutdatedOutput(output)
+}
+
+func parseOutdatedOutput(output []byte) ([]OutdatedPackage, error) {
+	var result brewOutdatedResponse
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse brew outdated output: %w", err)
+	}
+
+	packages := make([]OutdatedPackage, 0, len(result.Formulae)+len(result.Casks))
+	packages = append(packages, result.Formulae...)
+	for _, pkg := range result.Casks {
+		pkg.IsCask = true
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+type UpgradePlan struct {
+	Name    string `json:"name"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	IsCask  bool   `json:"is_cask"`
+	Skipped bool   `json:"skipped"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+var upgradePlanLineRegex = regexp.MustCompile(`^(\S+)\s+(.+?)\s+->\s+(\S+)$`)
+
+func (s *ServiceManager) UpgradePreview(ctx context.Context) ([]UpgradePlan, error) {
+	output, err := s.runBrewCommand(ctx, "upgrade", "--dry-run")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseUpgradePreview(string(output)), nil
+}
+
+func parseUpgradePreview(output string) []UpgradePlan {
+	plans := []UpgradePlan{}
+
+	var inSection, isCask, skipped bool
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		header := strings.TrimPrefix(line, "==> ")
+		switch {
+		case strings.HasPrefix(header, "Would upgrade"):
+			inSection, skipped = true, false
+			isCask = strings.Contains(header, "cask")
+			continue
+		case strings.HasPrefix(header, "Not upgrading"):
+			inSection, skipped = true, true
+			isCask = strings.Contains(header, "cask")
+			continue
+		case strings.HasPrefix(line, "==>"):
+			inSection = false
+			continue
+		}
+
+		if !inSection {
+			continue
+		}
+
+		match := upgradePlanLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		plan := UpgradePlan{
+			Name:    match[1],
+			From:    match[2],
+			To:      match[3],
+			IsCask:  isCask,
+			Skipped: skipped,
+		}
+		if skipped {
+			plan.Reason = "pinned"
+		}
+		plans = append(plans, plan)
+	}
+
+	return plans
+}
+
+func (s *ServiceManager) UpgradePackage(ctx context.Context, name string) error {
+	if err := validatePackageName(name); err != nil {
+		return err
+	}
+
+	_, err := s.runExclusive(ctx, append(packageTypeArgs(ctx, "upgrade"), name)...)
+	return classifyPackageError(err, name)
+}
+
+func (s *ServiceManager) UninstallPackage(ctx context.Context, name string, force bool) error {
+	if err := validatePackageName(name); err != nil {
+		return err
+	}
+
+	args := packageTypeArgs(ctx, "uninstall")
+	if force {
+		args = append(args, "--force", "--ignore-dependencies")
+	}
+
+	_, err := s.runExclusive(ctx, append(args, name)...)
+	return classifyPackageError(err, name)
+}
+
+var installOptions = map[string]bool{
+	"--build-from-source": true,
+	"--HEAD":              true,
+	"--force-bottle":      true,
+	"--keep-tmp":          true,
+	"--debug-symbols":     true,
+}
+
+func validateInstallOptions(options []string) error {
+	for _, opt := range options {
+		if !installOptions[opt] {
+			return &ValidationError{
+				Field:   "options",
+				Value:   opt,
+				Message: "unsupported option; allowed: --build-from-source, --HEAD, --force-bottle, --keep-tmp, --debug-symbols",
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ServiceManager) ReinstallPackage(ctx context.Context, name string, options ...string) ([]string, error) {
+	if err := validatePackageName(name); err != nil {
+		return nil, err
+	}
+	if err := validateInstallOptions(options); err != nil {
+		return nil, err
+	}
+
+	args := append(append([]string{"reinstall"}, options...), name)
+	_, err := s.runExclusive(ctx, args...)
+	if err != nil {
+		return nil, classifyPackageError(err, name)
+	}
+	return args, nil
+}
+
+func (s *ServiceManager) PinPackage(ctx context.Context, name string) (string, error) {
+	if err := validatePackageName(name); err != nil {
+		return "", err
+	}
+
+	version, err := s.InstalledVersion(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		return "", &NotInstalledError{Name: name}
+	}
+
+	if _, err := s.runExclusive(ctx, "pin", name); err != nil {
+		return "", classifyPackageError(err, name)
+	}
+	return version, nil
+}
+
+func (s *ServiceManager) UnpinPackage(ctx context.Context, name string) error {
+	if err := validatePackageName(name); err != nil {
+		return err
+	}
+
+	_, err := s.runExclusive(ctx, "unpin", name)
+	return err
+}
+
+type PartialInstallError struct {
+	Name      string
+	CleanedUp bool
+	Err       error
+}
+
+func (e *PartialInstallError) Error() string {
+	if e.CleanedUp {
+		return fmt.Sprintf("install of %s failed midway; partial install was cleaned up: %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf("install of %s failed midway and may have left a partial install: %v", e.Name, e.Err)
+}
+
+func (e *PartialInstallError) Unwrap() error {
+	return e.Err
+}
+
+// partialInstallMessages mark failures that happen after brew has started
+// writing the keg. Download failures also report "Failure while executing"
+// (for curl or git) but leave nothing behind, so only a failed bottle
+// extraction counts.
+var partialInstallMessages = []string{
+	"An exception occurred within a child process",
+	"Failure while executing; `tar",
+	"did not build",
+	"BuildError",
+	"Failed to build",
+	"make: ***",
+	"Error: Permission denied @ apply2files",
+}
+
+func (s *ServiceManager) InstallPackage(ctx context.Context, name string, cleanupOnFailure bool) (string, error) {
+	if err := validateQualifiedName(name); err != nil {
+		return "", err
+	}
+
+	output, err := s.runExclusive(ctx, append(packageTypeArgs(ctx, "install"), name)...)
+	if err != nil {
+		if stderrContains(err, "already installed") {
+			return "", &AlreadyInstalledError{Name: name}
+		}
+		if !stderrContains(err, partialInstallMessages...) {
+			return "", classifyPackageError(err, name)
+		}
+
+		partial := &PartialInstallError{Name: name, Err: err}
+		if cleanupOnFailure {
+			if _, cleanupErr := s.runExclusive(context.WithoutCancel(ctx), "uninstall", "--force", name); cleanupErr != nil {
+				logFromCtx(ctx).Warnf("Cleanup after failed install of %s failed: %v", name, cleanupErr)
+			} else {
+				partial.CleanedUp = true
+			}
+		}
+		return "", partial
+	}
+	return string(output), nil
+}
+
+func (s *ServiceManager) InstallVersion(ctx context.Context, name, version string) (string, error) {
+	if err := validatePackageName(name); err != nil {
+		return "", err
+	}
+	if err := validateVersion(version); err != nil {
+		return "", err
+	}
+
+	versioned := name + "@" + version
+	if err := validatePackageName(versioned); err != nil {
+		return "", err
+	}
+
+	output, err := s.runExclusive(ctx, "install", versioned)
+	if err != nil {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "No available formula") {
+			return "", &VersionNotAvailableError{Name: name, Version: version}
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (s *ServiceManager) IsAvailable() bool {
+	_, err := exec.LookPath(s.config.BrewPath)
+	return err == nil
+}
+
+func (s *ServiceManager) Version(ctx context.Context) (string, error) {
+	output, err := s.runBrewCommand(ctx, "--version")
+	if err != nil {
+		return "", err
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return line, nil
+}
+
+func (s *ServiceManager) Update(ctx context.Context) (string, error) {
+	output, err := s.runExclusive(ctx, "update")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (s *ServiceManager) Cleanup(ctx context.Context) (string, error) {
+	output, err := s.runExclusive(ctx, "cleanup", "--prune=all")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+var freedSpaceRegex = regexp.MustCompile(`freed approximately ([\d.]+\s*[KMGT]?B)`)
+
+func ParseFreedSpace(output string) string {
+	match := freedSpaceRegex.FindStringSubmatch(output)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func (s *ServiceManager) GetAnalytics(ctx context.Context) (bool, error) {
+	output, err := s.runBrewCommand(ctx, "analytics", "state")
+	if err != nil {
+		return false, err
+	}
+
+	return parseAnalyticsState(string(output))
+}
+
+func (s *ServiceManager) SetAnalytics(ctx context.Context, enabled bool) error {
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+
+	_, err := s.runExclusive(ctx, "analytics", state)
+	return err
+}
+
+func parseAnalyticsState(output string) (bool, error) {
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "analytics are disabled"):
+		return false, nil
+	case strings.Contains(lower, "analytics are enabled"):
+		return true, nil
+	default:
+		return false, fmt.Errorf("unrecognized brew analytics state output: %q", strings.TrimSpace(output))
+	}
+}
+
+func (s *ServiceManager) Doctor(ctx context.Context) (string, []DoctorIssue, error) {
+	output, err := s.runBrewCommand(ctx, "doctor")
+
+	var cmdErr *CommandError
+	if err != nil && !errors.As(err, &cmdErr) {
+		return "", nil, err
+	}
+
+	outputStr := string(output)
+	if cmdErr != nil {
+		outputStr = cmdErr.Stderr
+	}
+
+	issues := parseDoctorOutput(outputStr)
+
+	return outputStr, issues, nil
+}
+
+type DoctorIssue struct {
+	Type    string `json:"type"`    
+
+	Message string `json:"message"` 
+
+}
+
+func parseDoctorOutput(output string) []DoctorIssue {
+	var issues []DoctorIssue
+
+	lines := strings.Split(output, "\n")
+	var currentIssue string
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if currentIssue != "" {
+				issueType := "warning"
+				if strings.Contains(strings.ToLower(currentIssue), "error") {
+					issueType = "error"
+				}
+				issues = append(issues, DoctorIssue{
+					Type:    issueType,
+					Message: currentIssue,
+				})
+				currentIssue = ""
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "Warning:") || strings.HasPrefix(line, "Error:") {
+			if currentIssue != "" {
+				issueType := "warning"
+				if strings.Contains(strings.ToLower(currentIssue), "error") {
+					issueType = "error"
+				}
+				issues = append(issues, DoctorIssue{
+					Type:    issueType,
+					Message: currentIssue,
+				})
+			}
+			currentIssue = line
+		} else if currentIssue != "" {
+			currentIssue += " " + line
+		}
+	}
+
+	if currentIssue != "" {
+		issueType := "warning"
+		if strings.Contains(strings.ToLower(currentIssue), "error") {
+			issueType = "error"
+		}
+		issues = append(issues, DoctorIssue{
+			Type:    issueType,
+			Message: currentIssue,
+		})
+	}
+
+	return issues
+}
+
+func (s *ServiceManager) Info(ctx context.Context, name string) (*Package, error) {
+	if err := validatePackageName(name); err != nil {
+		return nil, err
+	}
+
+	output, err := s.runBrewCommand(ctx, append(packageTypeArgs(ctx, "info", "--json=v2"), name)...)
+	if err != nil {
+		return nil, classifyPackageError(err, name)
+	}
+
+	var result brewInfoResponse
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info output: %w", err)
+	}
+
+	if len(result.Formulae) > 0 {
+		pkg := result.Formulae[0]
+		return &pkg, nil
+	}
+	if len(result.Casks) > 0 {
+		pkg := result.Casks[0].toPackage()
+		return &pkg, nil
+	}
+
+	return nil, fmt.Errorf("brew info returned no results for %q", name)
+}
+
+// maxRawInfoBytes caps InfoRaw, which hands brew's output to the client
+// untouched. A single package's info document is a few kilobytes, so the
+// general MaxOutputBytes limit is far more than it ever needs.
+const maxRawInfoBytes = 1024 * 1024
+
+func (s *ServiceManager) InfoRaw(ctx context.Context, name string) ([]byte, error) {
+	if err := validatePackageName(name); err != nil {
+		return nil, err
+	}
+
+	limit := min(s.config.MaxOutputBytes, maxRawInfoBytes)
+	output, err := s.runBrewCommandLimit(ctx, limit, append(packageTypeArgs(ctx, "info", "--json=v2"), name)...)
+	if err != nil {
+		return nil, classifyPackageError(err, name)
+	}
+	return output, nil
+}
+
+type AmbiguousPackageError struct {
+	Name string
+}
+
+func (e *AmbiguousPackageError) Error() string {
+	return fmt.Sprintf("%s exists as both a formula and a cask; specify type=formula or type=cask", e.Name)
+}
+
+// IsAmbiguous reports whether name is both a formula and a cask. It checks
+// the cached catalog (or the cached installed lists when installedOnly is
+// set) instead of asking brew about the name directly, so mutations do not
+// pay for two extra brew invocations.
+func (s *ServiceManager) IsAmbiguous(ctx context.Context, name string, installedOnly bool) (bool, error) {
+	if err := validateQualifiedName(name); err != nil {
+		return false, err
+	}
+
+	var formulae, casks []string
+	err := s.fanOut(ctx, 2, func(ctx context.Context, i int) error {
+		var err error
+		switch {
+		case i == 0 && installedOnly:
+			formulae, err = s.cachedList(ctx, "list", "--formula", "-1")
+		case i == 0:
+			formulae, err = s.AllFormulae(ctx)
+		case installedOnly:
+			casks, err = s.cachedList(ctx, "list", "--cask", "-1")
+		default:
+			casks, err = s.AllCasks(ctx)
+		}
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(formulae, name) && slices.Contains(casks, name), nil
 }
 
-func (s *ServiceManager) UpgradePackage(ctx context.Context, name string) error {
-	if err := validatePackageName(name); err != nil {
-		return err
+func (s *ServiceManager) InstalledVersion(ctx context.Context, name string) (string, error) {
+	pkg, err := s.Info(ctx, name)
+	if err != nil {
+		return "", err
 	}
 
-	_, err := s.runBrewCommand(ctx, "upgrade", name)
-	return err
+	if len(pkg.Installed) == 0 {
+		return "", nil
+	}
+	return pkg.Installed[len(pkg.Installed)-1].Version, nil
 }
 
-func (s *ServiceManager) UninstallPackage(ctx context.Context, name string) error {
-	if err := validatePackageName(name); err != nil {
-		return err
+func (s *ServiceManager) CheckConflicts(ctx context.Context, name string) ([]string, error) {
+	pkg, err := s.Info(ctx, name)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := s.runBrewCommand(ctx, "uninstall", name)
-	return err
-}
+	if len(pkg.ConflictsWith) == 0 {
+		return []string{}, nil
+	}
 
-func (s *ServiceManager) ReinstallPackage(ctx context.Context, name string) error {
-	if err := validatePackageName(name); err != nil {
-		return err
+	installed, err := s.ListInstalled(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := s.runBrewCommand(ctx, "reinstall", name)
-	return err
-}
+	installedNames := make(map[string]bool, len(installed)*2)
+	for _, p := range installed {
+		installedNames[p.Name] = true
+		installedNames[p.FullName] = true
+	}
 
-func (s *ServiceManager) PinPackage(ctx context.Context, name string) error {
-	if err := validatePackageName(name); err != nil {
-		return err
+	conflicts := []string{}
+	for _, c := range pkg.ConflictsWith {
+		if installedNames[c] {
+			conflicts = append(conflicts, c)
+		}
 	}
 
-	_, err := s.runBrewCommand(ctx, "pin", name)
-	return err
+	return conflicts, nil
 }
 
-func (s *ServiceManager) UnpinPackage(ctx context.Context, name string) error {
+func (s *ServiceManager) Dependencies(ctx context.Context, name string) ([]string, error) {
 	if err := validatePackageName(name); err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err := s.runBrewCommand(ctx, "unpin", name)
-	return err
+	return s.cachedList(ctx, "deps", name)
 }
 
-func (s *ServiceManager) InstallPackage(ctx context.Context, name string) error {
+func (s *ServiceManager) Dependents(ctx context.Context, name string) ([]string, error) {
 	if err := validatePackageName(name); err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err := s.runBrewCommand(ctx, "install", name)
-	return err
+	return s.cachedList(ctx, "uses", "--installed", name)
 }
 
-func (s *ServiceManager) Update(ctx context.Context) (string, error) {
-	output, err := s.runBrewCommand(ctx, "update")
+func (s *ServiceManager) cachedList(ctx context.Context, args ...string) ([]string, error) {
+	key := fmt.Sprintf("%d|%s|%s", s.generation.Load(), prefixFromContext(ctx), strings.Join(args, " "))
+	if cached, ok := s.depsCache.Get(key); ok {
+		return append([]string(nil), cached...), nil
+	}
+
+	output, err := s.runBrewCommand(ctx, args...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return string(output), nil
+
+	result := strings.Fields(string(output))
+	s.depsCache.Put(key, result)
+	return append([]string(nil), result...), nil
 }
 
-func (s *ServiceManager) Cleanup(ctx context.Context) (string, error) {
-	output, err := s.runBrewCommand(ctx, "cleanup", "--prune=all")
+func (s *ServiceManager) DepsSize(ctx context.Context, name string) (int64, error) {
+	deps, err := s.Dependencies(ctx, name)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-	return string(output), nil
+
+	pkgs, err := s.infoMany(ctx, append(deps, name)...)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, pkg := range pkgs {
+		total += pkg.InstalledSize
+	}
+	return total, nil
 }
 
-func (s *ServiceManager) Doctor(ctx context.Context) (string, []DoctorIssue, error) {
-	output, err := s.runBrewCommand(ctx, "doctor")
+const infoBatchSize = 50
 
-	var cmdErr *CommandError
-	if err != nil && !errors.As(err, &cmdErr) {
-		return "", nil, err
+func (s *ServiceManager) infoMany(ctx context.Context, names ...string) ([]Package, error) {
+	for _, name := range names {
+		if err := validateQualifiedName(name); err != nil {
+			return nil, err
+		}
 	}
 
-	outputStr := string(output)
-	if cmdErr != nil {
-		outputStr = cmdErr.Stderr
+	batches := make([][]string, 0, len(names)/infoBatchSize+1)
+	for start := 0; start < len(names); start += infoBatchSize {
+		batches = append(batches, names[start:min(start+infoBatchSize, len(names))])
 	}
 
-	issues := parseDoctorOutput(outputStr)
+	results := make([]brewInfoResponse, len(batches))
+	err := s.fanOut(ctx, len(batches), func(ctx context.Context, i int) error {
+		output, err := s.runBrewCommand(ctx, append([]string{"info", "--json=v2"}, batches[i]...)...)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(output, &results[i]); err != nil {
+			return fmt.Errorf("failed to parse brew info output: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return outputStr, issues, nil
+	packages := make([]Package, 0, len(names))
+	for _, result := range results {
+		packages = append(packages, result.Formulae...)
+		for _, cask := range result.Casks {
+			packages = append(packages, cask.toPackage())
+		}
+	}
+	return packages, nil
 }
 
-type DoctorIssue struct {
-	Type    string `json:"type"`    
-
-	Message string `json:"message"` 
+type Popularity struct {
+	Installs30d  int64 `json:"installs_30d"`
+	Installs90d  int64 `json:"installs_90d"`
+	Installs365d int64 `json:"installs_365d"`
+	Available    bool  `json:"available"`
+}
 
+type brewAnalyticsInfo struct {
+	Analytics *struct {
+		Install map[string]map[string]int64 `json:"install"`
+	} `json:"analytics"`
 }
 
-func parseDoctorOutput(output string) []DoctorIssue {
-	var issues []DoctorIssue
+func (s *ServiceManager) Popularity(ctx context.Context, name string) (*Popularity, error) {
+	if err := validatePackageName(name); err != nil {
+		return nil, err
+	}
 
-	lines := strings.Split(output, "\n")
-	var currentIssue string
+	output, err := s.runBrewCommand(ctx, "info", "--analytics", "--json=v2", name)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			if currentIssue != "" {
-				issueType := "warning"
-				if strings.Contains(strings.ToLower(currentIssue), "error") {
-					issueType = "error"
-				}
-				issues = append(issues, DoctorIssue{
-					Type:    issueType,
-					Message: currentIssue,
-				})
-				currentIssue = ""
-			}
-			continue
-		}
+	var result struct {
+		Formulae []brewAnalyticsInfo `json:"formulae"`
+		Casks    []brewAnalyticsInfo `json:"casks"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info output: %w", err)
+	}
 
-		if strings.HasPrefix(line, "Warning:") || strings.HasPrefix(line, "Error:") {
-			if currentIssue != "" {
-				issueType := "warning"
-				if strings.Contains(strings.ToLower(currentIssue), "error") {
-					issueType = "error"
-				}
-				issues = append(issues, DoctorIssue{
-					Type:    issueType,
-					Message: currentIssue,
-				})
-			}
-			currentIssue = line
-		} else if currentIssue != "" {
-			currentIssue += " " + line
-		}
+	var info brewAnalyticsInfo
+	switch {
+	case len(result.Formulae) > 0:
+		info = result.Formulae[0]
+	case len(result.Casks) > 0:
+		info = result.Casks[0]
 	}
 
-	if currentIssue != "" {
-		issueType := "warning"
-		if strings.Contains(strings.ToLower(currentIssue), "error") {
-			issueType = "error"
-		}
-		issues = append(issues, DoctorIssue{
-			Type:    issueType,
-			Message: currentIssue,
-		})
+	popularity := &Popularity{}
+	if info.Analytics == nil || len(info.Analytics.Install) == 0 {
+		return popularity, nil
 	}
 
-	return issues
+	popularity.Available = true
+	popularity.Installs30d = sumCounts(info.Analytics.Install["30d"])
+	popularity.Installs90d = sumCounts(info.Analytics.Install["90d"])
+	popularity.Installs365d = sumCounts(info.Analytics.Install["365d"])
+	return popularity, nil
+}
+
+func sumCounts(counts map[string]int64) int64 {
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total
 }
 
 func (s *ServiceManager) GetPackageSize(ctx context.Context, name string) (int64, error) {
@@ -407,7 +1324,7 @@ func (s *ServiceManager) GetPackageSize(ctx context.Context, name string) (int64
 	return 0, nil
 }
 
-func (s *ServiceManager) ListServices(ctx context.Context) ([]Service, error) {
+func (s *ServiceManager) listServiceEntries(ctx context.Context) ([]serviceListEntry, error) {
 	output, err := s.runBrewCommand(ctx, "services", "list", "--json")
 	if err != nil {
 		return nil, err
@@ -417,6 +1334,28 @@ func (s *ServiceManager) ListServices(ctx context.Context) ([]Service, error) {
 	if err := json.Unmarshal(output, &entries); err != nil {
 		return nil, fmt.Errorf("failed to parse brew services output: %w", err)
 	}
+	return entries, nil
+}
+
+func (s *ServiceManager) IsServiceRunning(ctx context.Context, name string) (bool, error) {
+	entries, err := s.listServiceEntries(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry.Status == "started", nil
+		}
+	}
+	return false, nil
+}
+
+func (s *ServiceManager) ListServices(ctx context.Context) ([]Service, error) {
+	entries, err := s.listServiceEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	homepageMap := make(map[string]string)
 	if packages, err := s.ListInstalled(ctx); err == nil {
@@ -442,44 +1381,71 @@ func (s *ServiceManager) ListServices(ctx context.Context) ([]Service, error) {
 }
 
 func (s *ServiceManager) StartService(ctx context.Context, name string) error {
-	if err := validatePackageName(name); err != nil {
+	if err := validateServiceName(name); err != nil {
 		return err
 	}
 
-	_, err := s.runBrewCommand(ctx, "services", "start", name)
+	_, err := s.runExclusive(ctx, "services", "start", name)
 	return err
 }
 
 func (s *ServiceManager) StopService(ctx context.Context, name string) error {
-	if err := validatePackageName(name); err != nil {
+	if err := validateServiceName(name); err != nil {
 		return err
 	}
 
-	_, err := s.runBrewCommand(ctx, "services", "stop", name)
+	_, err := s.runExclusive(ctx, "services", "stop", name)
 	return err
 }
 
 func (s *ServiceManager) RestartService(ctx context.Context, name string) error {
-	if err := validatePackageName(name); err != nil {
+	if err := validateServiceName(name); err != nil {
 		return err
 	}
 
-	_, err := s.runBrewCommand(ctx, "services", "restart", name)
+	_, err := s.runExclusive(ctx, "services", "restart", name)
 	return err
 }
 
+func (s *ServiceManager) RestartAll(ctx context.Context) (string, error) {
+	output, err := s.runExclusive(ctx, "services", "restart", "--all")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (s *ServiceManager) StopAll(ctx context.Context) (string, error) {
+	output, err := s.runExclusive(ctx, "services", "stop", "--all")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+var serviceOutputNameRegex = regexp.MustCompile("Successfully \\w+ `([^`]+)`")
+
+func ParseAffectedServices(output string) []string {
+	seen := make(map[string]bool)
+	affected := []string{}
+
+	for _, match := range serviceOutputNameRegex.FindAllStringSubmatch(output, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			affected = append(affected, match[1])
+		}
+	}
+	return affected
+}
+
 func (s *ServiceManager) Search(ctx context.Context, query string) ([]string, error) {
 	if query == "" {
 		return nil, nil 
 
 	}
 
-	if len(query) > maxPackageNameLength {
-		return nil, &ValidationError{
-			Field:   "query",
-			Value:   query[:20] + "...",
-			Message: "search query too long",
-		}
+	if err := validateSearchQuery(query); err != nil {
+		return nil, err
 	}
 
 	output, err := s.runBrewCommand(ctx, "search", query)
@@ -495,6 +1461,34 @@ func (s *ServiceManager) Search(ctx context.Context, query string) ([]string, er
 	return parseSearchOutput(string(output)), nil
 }
 
+func validateSearchQuery(query string) error {
+	if len(query) > maxPackageNameLength {
+		return &ValidationError{
+			Field:   "query",
+			Value:   query[:20] + "...",
+			Message: "search query too long",
+		}
+	}
+
+	if strings.HasPrefix(query, "-") {
+		return &ValidationError{
+			Field:   "query",
+			Value:   query,
+			Message: "search query must not start with '-'",
+		}
+	}
+
+	if strings.IndexFunc(query, unicode.IsControl) >= 0 {
+		return &ValidationError{
+			Field:   "query",
+			Value:   strconv.Quote(query),
+			Message: "search query contains control characters",
+		}
+	}
+
+	return nil
+}
+
 func parseSearchOutput(output string) []string {
 	seen := make(map[string]bool)
 	var results []string
@@ -532,9 +1526,14 @@ func (s *ServiceManager) GetPackageUsage(ctx context.Context, name string) (stri
 		return "", err
 	}
 
-	cheatSheet, err := s.fetchCheatSheet(ctx, name)
-	if err == nil && cheatSheet != "" && !strings.Contains(cheatSheet, "Unknown topic") {
-		return cheatSheet, nil
+	note := "No community cheat sheet found."
+	if s.config.DisableCheatSheet {
+		note = "External cheat sheet lookups are disabled."
+	} else {
+		cheatSheet, err := s.fetchCheatSheet(ctx, name)
+		if err == nil && cheatSheet != "" && !strings.Contains(cheatSheet, "Unknown topic") {
+			return cheatSheet, nil
+		}
 	}
 
 	output, err := s.runBrewCommand(ctx, "info", name)
@@ -542,7 +1541,35 @@ func (s *ServiceManager) GetPackageUsage(ctx context.Context, name string) (stri
 		return "No usage examples found. 'brew info' also failed.", nil
 	}
 
-	return fmt.Sprintf("No community cheat sheet found. Showing 'brew info' output:\n\n%s", string(output)), nil
+	return fmt.Sprintf("%s Showing 'brew info' output:\n\n%s", note, string(output)), nil
+}
+
+const manPageTimeout = 10 * time.Second
+
+var manOverstrikeRegex = regexp.MustCompile(".\x08")
+
+func (s *ServiceManager) ManPage(ctx context.Context, name string) (string, error) {
+	if err := validatePackageName(name); err != nil {
+		return "", err
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, manPageTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, "man", name)
+	cmd.Env = append(os.Environ(), "MANPAGER=cat", "PAGER=cat", "MANWIDTH=80")
+	output, err := cmd.Output()
+	if err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			return "", &TimeoutError{
+				Command: "man " + name,
+				Timeout: manPageTimeout,
+			}
+		}
+		return fmt.Sprintf("No man page found for %s.", name), nil
+	}
+
+	return manOverstrikeRegex.ReplaceAllString(string(output), ""), nil
 }
 
 func (s *ServiceManager) fetchCheatSheet(ctx context.Context, name string) (string, error) {
@@ -553,7 +1580,10 @@ func (s *ServiceManager) fetchCheatSheet(ctx context.Context, name string) (stri
 		return "", err
 	}
 
-	req.Header.Set("User-Agent", "curl/7.64.1")
+	req.Header.Set("User-Agent", s.config.CheatSheetUserAgent)
+	if s.config.CheatSheetAccept != "" {
+		req.Header.Set("Accept", s.config.CheatSheetAccept)
+	}
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -574,36 +1604,161 @@ func (s *ServiceManager) fetchCheatSheet(ctx context.Context, name string) (stri
 	return string(body), nil
 }
 
+func (s *ServiceManager) runExclusive(ctx context.Context, args ...string) ([]byte, error) {
+	var output []byte
+	opType, target := operationFromArgs(args)
+	err := s.withMutation(ctx, opType, target, func() error {
+		var err error
+		output, err = s.runBrewCommand(ctx, args...)
+		return err
+	})
+	return output, err
+}
+
+// withMutation runs fn as an exclusive operation and then bumps the
+// generation, invalidating every cache keyed by it. All paths that change
+// installed state go through here.
+func (s *ServiceManager) withMutation(ctx context.Context, opType, target string, fn func() error) error {
+	err := s.WithOperation(ctx, opType, target, fn)
+	s.generation.Add(1)
+	return err
+}
+
+var readOnlyCommands = map[string]bool{
+	"info":         true,
+	"list":         true,
+	"search":       true,
+	"deps":         true,
+	"uses":         true,
+	"outdated":     true,
+	"leaves":       true,
+	"config":       true,
+	"--version":    true,
+	"--cache":      true,
+	"--prefix":     true,
+	"--repository": true,
+	"formulae":     true,
+	"casks":        true,
+	"missing":      true,
+}
+
+var mutatingCommands = map[string]bool{
+	"install":    true,
+	"uninstall":  true,
+	"reinstall":  true,
+	"upgrade":    true,
+	"update":     true,
+	"cleanup":    true,
+	"pin":        true,
+	"unpin":      true,
+	"tap":        true,
+	"untap":      true,
+	"autoremove": true,
+}
+
+func commandCategory(args []string) (CommandCategory, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+
+	switch {
+	case readOnlyCommands[args[0]]:
+		return CategoryRead, true
+	case mutatingCommands[args[0]]:
+		return CategoryMutate, true
+	case args[0] == "services" || args[0] == "analytics":
+		if len(args) > 1 && (args[1] == "list" || args[1] == "info" || args[1] == "state") {
+			return CategoryRead, true
+		}
+		return CategoryMutate, true
+	default:
+		return "", false
+	}
+}
+
+func (s *ServiceManager) commandTimeout(args []string) time.Duration {
+	if category, ok := commandCategory(args); ok {
+		if timeout, ok := s.config.CommandTimeouts[category]; ok {
+			return timeout
+		}
+	}
+	return s.config.CommandTimeout
+}
+
+func (s *ServiceManager) commandEnv(ctx context.Context) []string {
+	env := os.Environ()
+	if noAutoUpdateFromContext(ctx) {
+		env = append(env, "HOMEBREW_NO_AUTO_UPDATE=1")
+	}
+	if s.config.ProxyURL != "" {
+		env = append(env,
+			"HTTP_PROXY="+s.config.ProxyURL,
+			"HTTPS_PROXY="+s.config.ProxyURL,
+			"ALL_PROXY="+s.config.ProxyURL,
+			"http_proxy="+s.config.ProxyURL,
+			"https_proxy="+s.config.ProxyURL,
+		)
+	}
+	return env
+}
+
 func (s *ServiceManager) runBrewCommand(ctx context.Context, args ...string) ([]byte, error) {
+	return s.runBrewCommandLimit(ctx, s.config.MaxOutputBytes, args...)
+}
+
+func (s *ServiceManager) runBrewCommandLimit(ctx context.Context, limit int64, args ...string) ([]byte, error) {
+
+	binary, err := s.brewBinary(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	cmdCtx, cancel := context.WithTimeout(ctx, s.config.CommandTimeout)
+	timeout := s.commandTimeout(args)
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "brew", args...)
-	output, err := cmd.Output()
+	logFromCtx(ctx).Debugf("Running brew %s", strings.Join(args, " "))
+
+	stdout := &cappedBuffer{limit: limit, onExceed: cancel}
+	stderrBuf := &cappedBuffer{limit: maxStderrBytes}
+
+	cmd := exec.CommandContext(cmdCtx, binary, args...)
+	cmd.Env = s.commandEnv(ctx)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderrBuf
+	err = cmd.Run()
+	output := stdout.Bytes()
+
+	if stdout.exceeded {
+		return nil, &OutputTooLargeError{
+			Command: strings.Join(args, " "),
+			Limit:   limit,
+		}
+	}
 
 	if err != nil {
 
 		if cmdCtx.Err() == context.DeadlineExceeded {
 			return nil, &TimeoutError{
 				Command: strings.Join(args, " "),
-				Timeout: s.config.CommandTimeout,
+				Timeout: timeout,
 			}
 		}
 
-		stderr := ""
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			stderr = string(exitErr.Stderr)
+		stderr := string(stderrBuf.Bytes())
+		if stderrBuf.exceeded {
+			stderr += "... (truncated)"
+		}
 
-			if len(stderr) > 1024 {
-				stderr = stderr[:1024] + "... (truncated)"
-			}
+		if isBrewLocked(stderr) {
+			return nil, &BrewLockedError{Command: args[0]}
 		}
 
 		return nil, &CommandError{
 			Command: args[0],
 			Args:    args[1:],
 			Stderr:  stderr,
+			Stdout:  string(output),
 			Cause:   err,
 		}
 	}
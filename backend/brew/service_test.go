@@ -0,0 +1,192 @@
+package brew
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const deprecatedFixture = `{
+  "formulae": [
+    {
+      "name": "python@3.8",
+      "full_name": "python@3.8",
+      "desc": "Interpreted, interactive, object-oriented programming language",
+      "versions": {"stable": "3.8.20", "bottle": true},
+      "installed": [{"version": "3.8.20", "installed_on_request": true, "time": 1700000000}],
+      "deprecated": true,
+      "deprecation_date": "2024-10-14",
+      "deprecation_reason": "unsupported",
+      "disabled": false,
+      "disable_date": null,
+      "disable_reason": null
+    },
+    {
+      "name": "wget",
+      "full_name": "wget",
+      "installed": [{"version": "1.24.5", "installed_on_request": true, "time": 1700000000}],
+      "deprecated": false,
+      "deprecation_reason": null,
+      "disabled": false,
+      "disable_reason": null
+    }
+  ],
+  "casks": []
+}`
+
+func TestListInstalledDeprecatedFormula(t *testing.T) {
+	s, _ := newFakeBrew(t, "cat <<'JSON'\n"+deprecatedFixture+"\nJSON")
+
+	pkgs, err := s.ListInstalled(context.Background())
+	if err != nil {
+		t.Fatalf("ListInstalled: %v", err)
+	}
+	if len(pkgs) != 2 {
+		t.Fatalf("got %d packages, want 2", len(pkgs))
+	}
+
+	byName := make(map[string]Package, len(pkgs))
+	for _, pkg := range pkgs {
+		byName[pkg.Name] = pkg
+	}
+
+	python := byName["python@3.8"]
+	if !python.Deprecated || python.DeprecationReason != "unsupported" || python.Disabled {
+		t.Errorf("python@3.8 = deprecated %v (%q), disabled %v; want deprecated unsupported, not disabled",
+			python.Deprecated, python.DeprecationReason, python.Disabled)
+	}
+
+	wget := byName["wget"]
+	if wget.Deprecated || wget.DeprecationReason != "" || wget.Disabled {
+		t.Errorf("wget = deprecated %v (%q), disabled %v; want neither",
+			wget.Deprecated, wget.DeprecationReason, wget.Disabled)
+	}
+}
+
+func TestRunBrewCommandLocked(t *testing.T) {
+	tests := []string{
+		"Error: Another active Homebrew update process is already in progress.\nPlease wait for it to finish or terminate it to continue.",
+		"Error: A `brew install wget` process has already locked /opt/homebrew/Cellar/wget.\nPlease wait for it to finish or terminate it to continue.",
+	}
+
+	for _, stderr := range tests {
+		s, _ := newFakeBrew(t, "printf '%s\\n' '"+stderr+"' >&2\nexit 1")
+
+		_, err := s.runBrewCommand(context.Background(), "install", "wget")
+		var lockedErr *BrewLockedError
+		if !errors.As(err, &lockedErr) {
+			t.Errorf("stderr %q: got %v, want BrewLockedError", stderr, err)
+			continue
+		}
+		if lockedErr.Command != "install" {
+			t.Errorf("BrewLockedError.Command = %q, want install", lockedErr.Command)
+		}
+	}
+}
+
+func TestRunBrewCommandOtherFailure(t *testing.T) {
+	s, _ := newFakeBrew(t, "echo 'Error: No available formula with the name \"nope\".' >&2\nexit 1")
+
+	_, err := s.runBrewCommand(context.Background(), "info", "nope")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("got %v, want CommandError", err)
+	}
+}
+
+func TestSearchEmptyQuerySkipsBrew(t *testing.T) {
+	s, calls := newFakeBrew(t, "exit 0")
+
+	results, err := s.Search(context.Background(), "")
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Search(\"\") = %v, %v; want no results", results, err)
+	}
+	if got := brewCalls(t, calls); len(got) != 0 {
+		t.Fatalf("brew ran %v for an empty query", got)
+	}
+}
+
+func TestClassifyPackageError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   string
+	}{
+		{`Error: No available formula with the name "nope". Did you mean nop?`, "not found"},
+		{`Error: No available formula or cask with the name "nope".`, "not found"},
+		{"Error: No casks found for nope.", "not found"},
+		{`Error: No formulae or casks found for "nope".`, "not found"},
+		{`Error: Cask 'nope' is unavailable: No Cask with this name exists.`, "not found"},
+		{"Error: No such keg: /opt/homebrew/Cellar/wget", "not installed"},
+		{"Error: wget is not installed", "not installed"},
+		{"Error: Failed to download resource \"wget\"", "command"},
+	}
+
+	for _, tt := range tests {
+		err := classifyPackageError(&CommandError{Command: "uninstall", Stderr: tt.stderr}, "nope")
+
+		var got string
+		var notFoundErr *PackageNotFoundError
+		var notInstalledErr *NotInstalledError
+		var cmdErr *CommandError
+		switch {
+		case errors.As(err, &notFoundErr):
+			got = "not found"
+		case errors.As(err, &notInstalledErr):
+			got = "not installed"
+		case errors.As(err, &cmdErr):
+			got = "command"
+		}
+		if got != tt.want {
+			t.Errorf("classifyPackageError(%q) = %v, want %s", tt.stderr, err, tt.want)
+		}
+	}
+}
+
+func TestIsAmbiguousUsesCachedCatalog(t *testing.T) {
+	s, calls := newFakeBrew(t, `case "$1" in
+formulae) printf 'docker\nwget\n' ;;
+casks) printf 'docker\nfirefox\n' ;;
+esac`)
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name string
+		want bool
+	}{
+		{"docker", true},
+		{"wget", false},
+		{"firefox", false},
+	} {
+		got, err := s.IsAmbiguous(ctx, tt.name, false)
+		if err != nil {
+			t.Fatalf("IsAmbiguous(%q): %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("IsAmbiguous(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := len(brewCalls(t, calls)); got != 2 {
+		t.Errorf("brew ran %d times, want 2 catalog fetches", got)
+	}
+}
+
+func TestInfoRawOutputLimit(t *testing.T) {
+	s, _ := newFakeBrew(t, fmt.Sprintf("head -c %d /dev/zero | tr '\\0' 'a'", maxRawInfoBytes+1))
+
+	_, err := s.InfoRaw(context.Background(), "wget")
+	var tooLargeErr *OutputTooLargeError
+	if !errors.As(err, &tooLargeErr) {
+		t.Fatalf("got %v, want OutputTooLargeError", err)
+	}
+	if tooLargeErr.Limit != maxRawInfoBytes {
+		t.Errorf("Limit = %d, want %d", tooLargeErr.Limit, maxRawInfoBytes)
+	}
+
+	s, _ = newFakeBrew(t, "echo '"+installedFixture+"'")
+	output, err := s.InfoRaw(context.Background(), "wget")
+	if err != nil || len(output) == 0 {
+		t.Fatalf("InfoRaw = %d bytes, %v; want the raw document", len(output), err)
+	}
+}
@@ -0,0 +1,62 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type ServiceNotFoundError struct {
+	Name string
+}
+
+func (e *ServiceNotFoundError) Error() string {
+	return fmt.Sprintf("no service named %s was found", e.Name)
+}
+
+type serviceInfoEntry struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	User     string `json:"user"`
+	File     string `json:"file"`
+	Running  bool   `json:"running"`
+	PID      int    `json:"pid"`
+	ExitCode *int   `json:"exit_code"`
+}
+
+func (s *ServiceManager) ServiceDetail(ctx context.Context, name string) (*Service, error) {
+	if err := validateServiceName(name); err != nil {
+		return nil, err
+	}
+
+	output, err := s.runBrewCommand(ctx, "services", "info", name, "--json")
+	if err != nil {
+		if stderrContains(err, notFoundMessages...) {
+			return nil, &ServiceNotFoundError{Name: name}
+		}
+		return nil, err
+	}
+
+	var entries []serviceInfoEntry
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse brew services info output: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, &ServiceNotFoundError{Name: name}
+	}
+
+	entry := entries[0]
+	service := &Service{
+		Name:     entry.Name,
+		Status:   entry.Status,
+		User:     entry.User,
+		Plist:    entry.File,
+		Running:  entry.Running,
+		PID:      entry.PID,
+		ExitCode: entry.ExitCode,
+	}
+	if pkg, err := s.Info(ctx, name); err == nil {
+		service.Homepage = pkg.Homepage
+	}
+	return service, nil
+}
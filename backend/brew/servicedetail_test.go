@@ -0,0 +1,31 @@
+package brew
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestServiceDetailNotFound(t *testing.T) {
+	s, _ := newFakeBrew(t, `echo 'Error: No available formula with the name "nope".' >&2; exit 1`)
+
+	_, err := s.ServiceDetail(context.Background(), "nope")
+	var notFoundErr *ServiceNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("got %v, want ServiceNotFoundError", err)
+	}
+}
+
+func TestServiceDetailOtherFailure(t *testing.T) {
+	s, _ := newFakeBrew(t, `echo 'Error: Permission denied @ rb_sysopen - /Library/LaunchDaemons' >&2; exit 1`)
+
+	_, err := s.ServiceDetail(context.Background(), "postgresql@16")
+	var notFoundErr *ServiceNotFoundError
+	if errors.As(err, &notFoundErr) {
+		t.Fatalf("got ServiceNotFoundError for an unrelated failure")
+	}
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("got %v, want CommandError", err)
+	}
+}
@@ -0,0 +1,73 @@
+package brew
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const serviceProbeTimeout = 1 * time.Second
+
+type ServiceHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Running   bool   `json:"running"`
+	Port      int    `json:"port,omitempty"`
+	Probed    bool   `json:"probed"`
+	Reachable bool   `json:"reachable"`
+}
+
+func (s *ServiceManager) ServiceHealth(ctx context.Context, name string) (*ServiceHealth, error) {
+	if err := validateServiceName(name); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.listServiceEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &ServiceHealth{Name: name, Status: "unknown"}
+	for _, entry := range entries {
+		if entry.Name == name {
+			health.Status = entry.Status
+			health.Running = entry.Status == "started"
+			break
+		}
+	}
+
+	port, ok := s.servicePort(name)
+	if !ok {
+		return health, nil
+	}
+
+	health.Port = port
+	health.Probed = true
+	health.Reachable = probePort(ctx, port)
+	return health, nil
+}
+
+func (s *ServiceManager) servicePort(name string) (int, bool) {
+	if port, ok := s.config.ServicePorts[name]; ok {
+		return port, true
+	}
+
+	base, _, _ := strings.Cut(name, "@")
+	port, ok := s.config.ServicePorts[base]
+	return port, ok
+}
+
+func probePort(ctx context.Context, port int) bool {
+	ctx, cancel := context.WithTimeout(ctx, serviceProbeTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,114 @@
+package brew
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+const (
+	maxStreamStderrLines = 20
+	maxStreamLineBytes   = 1024 * 1024
+)
+
+func (s *ServiceManager) InstallPackageStream(ctx context.Context, name string, onLine func(string)) error {
+	if err := validateQualifiedName(name); err != nil {
+		return err
+	}
+
+	return s.withMutation(ctx, "install", name, func() error {
+		return s.runBrewCommandStream(ctx, onLine, "install", name)
+	})
+}
+
+func (s *ServiceManager) runBrewCommandStream(ctx context.Context, onLine func(string), args ...string) error {
+	binary, err := s.brewBinary(ctx)
+	if err != nil {
+		return err
+	}
+
+	timeout := s.commandTimeout(args)
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logFromCtx(ctx).Debugf("Streaming brew %s", strings.Join(args, " "))
+
+	pr, pw := io.Pipe()
+	cmd := exec.CommandContext(cmdCtx, binary, args...)
+	cmd.Env = s.commandEnv(ctx)
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		pw.Close()
+		return &CommandError{Command: args[0], Args: args[1:], Cause: err}
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.CloseWithError(err)
+		waitErr <- err
+	}()
+
+	var tail []string
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+	scanner.Split(scanLinesOrCarriageReturns)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		onLine(line)
+
+		tail = append(tail, line)
+		if len(tail) > maxStreamStderrLines {
+			tail = tail[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// Keep draining so brew is not killed by a broken pipe; the exit
+		// status below still decides whether the command failed.
+		logFromCtx(ctx).Warnf("Stopped streaming output of brew %s: %v", args[0], err)
+		io.Copy(io.Discard, pr)
+	}
+	pr.Close()
+
+	if err := <-waitErr; err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			return &TimeoutError{
+				Command: strings.Join(args, " "),
+				Timeout: timeout,
+			}
+		}
+		stderr := strings.Join(tail, "\n")
+		if isBrewLocked(stderr) {
+			return &BrewLockedError{Command: args[0]}
+		}
+		return &CommandError{
+			Command: args[0],
+			Args:    args[1:],
+			Stderr:  stderr,
+			Cause:   err,
+		}
+	}
+
+	return nil
+}
+
+func scanLinesOrCarriageReturns(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
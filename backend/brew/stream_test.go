@@ -0,0 +1,86 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestInstallPackageStreamLongLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		lineSize int
+		wantLong bool
+	}{
+		{"line above the default scanner limit", 200 * 1024, true},
+		{"line above the stream limit", maxStreamLineBytes + 1024, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newFakeBrew(t, fmt.Sprintf("echo '==> Pouring wget--1.24.5.bottle.tar.gz'\nhead -c %d /dev/zero | tr '\\0' 'a'\necho\necho '==> Summary'", tt.lineSize))
+
+			var sawLong bool
+			err := s.InstallPackageStream(context.Background(), "wget", func(line string) {
+				if len(line) == tt.lineSize {
+					sawLong = true
+				}
+			})
+			if err != nil {
+				t.Fatalf("InstallPackageStream: %v", err)
+			}
+			if sawLong != tt.wantLong {
+				t.Errorf("long line delivered = %v, want %v", sawLong, tt.wantLong)
+			}
+		})
+	}
+}
+
+func TestInstallPackageStreamAcceptsQualifiedNames(t *testing.T) {
+	s, calls := newFakeBrew(t, "exit 0")
+
+	if err := s.InstallPackageStream(context.Background(), "someuser/tap/formula", func(string) {}); err != nil {
+		t.Fatalf("InstallPackageStream: %v", err)
+	}
+	if got := brewCalls(t, calls); len(got) != 1 || got[0] != "install someuser/tap/formula" {
+		t.Fatalf("brew calls = %q, want install someuser/tap/formula", got)
+	}
+}
+
+func TestProgressParser(t *testing.T) {
+	lines := []struct {
+		line      string
+		wantOK    bool
+		wantPhase string
+		percent   float64
+		target    string
+	}{
+		{"==> Fetching dependencies for wget: libunistring, gettext and libidn2", true, PhaseDownload, 0, "dependencies for wget: libunistring, gettext and libidn2"},
+		{"==> Downloading https://ghcr.io/v2/homebrew/core/wget/manifests/1.24.5", true, PhaseDownload, 0, "https://ghcr.io/v2/homebrew/core/wget/manifests/1.24.5"},
+		{"######################################################################### 100.0%", true, PhaseDownload, 100, "https://ghcr.io/v2/homebrew/core/wget/manifests/1.24.5"},
+		{"#################                                                          24.6%", true, PhaseDownload, 24.6, "https://ghcr.io/v2/homebrew/core/wget/manifests/1.24.5"},
+		{"Already downloaded: /Users/me/Library/Caches/Homebrew/downloads/wget--1.24.5.bottle.tar.gz", false, "", 0, ""},
+		{"==> Installing wget", true, PhaseBuild, 0, "wget"},
+		{"==> Pouring wget--1.24.5.arm64_sonoma.bottle.tar.gz", true, PhasePour, 0, "wget--1.24.5.arm64_sonoma.bottle.tar.gz"},
+		{"==> Linking Binary 'wget' to '/opt/homebrew/bin/wget'", true, PhaseLink, 0, "Binary 'wget' to '/opt/homebrew/bin/wget'"},
+		{"==> Caveats", true, PhaseCaveats, 0, "Caveats"},
+		{"==> Summary", true, PhaseSummary, 100, "Summary"},
+		{"🍺  /opt/homebrew/Cellar/wget/1.24.5: 92 files, 4.5MB", false, "", 0, ""},
+		{"==> Running `brew cleanup wget`...", false, "", 0, ""},
+	}
+
+	var parser ProgressParser
+	for _, tt := range lines {
+		event, ok := parser.Parse(tt.line)
+		if ok != tt.wantOK {
+			t.Errorf("Parse(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if event.Phase != tt.wantPhase || event.Percent != tt.percent || event.Target != tt.target {
+			t.Errorf("Parse(%q) = %+v, want phase %s percent %v target %q", tt.line, *event, tt.wantPhase, tt.percent, tt.target)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package brew
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateQualifiedName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"wget", true},
+		{"python@3.12", true},
+		{"someuser/tap/formula", true},
+		{"homebrew/cask-fonts/font-fira-code", true},
+		{"tap/name", false},
+		{"user/tap/name/extra", false},
+		{"user//name", false},
+		{"/tap/name", false},
+		{"user/tap/", false},
+		{"../../etc", false},
+		{"user/../name", false},
+		{"user/tap/..", false},
+		{"-user/tap/name", false},
+		{"user/tap/-name", false},
+		{"user/tap/na me", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		err := validateQualifiedName(tt.name)
+		if tt.valid && err != nil {
+			t.Errorf("validateQualifiedName(%q) = %v, want nil", tt.name, err)
+		}
+		if !tt.valid {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("validateQualifiedName(%q) = %v, want ValidationError", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		valid   bool
+	}{
+		{"1.2.3", true},
+		{"18.19.0_1", true},
+		{"3.12.0-rc1", true},
+		{"1.0+build.5", true},
+		{"HEAD-abc123", true},
+		{"", false},
+		{"1.2; rm -rf", false},
+		{"1.2.3 && ls", false},
+		{"$(whoami)", false},
+		{"-1.2", false},
+		{"../1.2", false},
+		{"1.2\n3", false},
+		{strings.Repeat("1", maxVersionLength+1), false},
+	}
+
+	for _, tt := range tests {
+		err := validateVersion(tt.version)
+		if tt.valid && err != nil {
+			t.Errorf("validateVersion(%q) = %v, want nil", tt.version, err)
+		}
+		if !tt.valid {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("validateVersion(%q) = %v, want ValidationError", tt.version, err)
+			}
+		}
+	}
+}
+
+func TestValidateSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		valid bool
+	}{
+		{"wget", true},
+		{"python@3", true},
+		{"café", true},
+		{"日本語", true},
+		{"/^node$/", true},
+		{"-rf", false},
+		{"--eval=system", false},
+		{"wget\x00", false},
+		{"wget\ninstall", false},
+		{strings.Repeat("a", maxPackageNameLength+1), false},
+	}
+
+	for _, tt := range tests {
+		err := validateSearchQuery(tt.query)
+		if tt.valid && err != nil {
+			t.Errorf("validateSearchQuery(%q) = %v, want nil", tt.query, err)
+		}
+		if !tt.valid {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("validateSearchQuery(%q) = %v, want ValidationError", tt.query, err)
+			}
+		}
+	}
+}
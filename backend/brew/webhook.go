@@ -0,0 +1,90 @@
+package brew
+
+import (
+	"brew-manager/logging"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const webhookSignatureHeader = "X-Brew-Manager-Signature"
+
+type WebhookEvent struct {
+	Operation  string `json:"operation"`
+	Target     string `json:"target,omitempty"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (s *ServiceManager) WebhookEnabled() bool {
+	return s.config.WebhookURL != ""
+}
+
+func (s *ServiceManager) NotifyWebhook(event WebhookEvent) {
+	if !s.WebhookEnabled() {
+		return
+	}
+
+	go func() {
+		if err := s.sendWebhook(event); err != nil {
+			logging.Warnf("Webhook notification for %s failed: %v", event.Operation, err)
+		}
+	}()
+}
+
+func (s *ServiceManager) sendWebhook(event WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.HTTPTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.WebhookSecret != "" {
+		req.Header.Set(webhookSignatureHeader, "sha256="+signPayload(s.config.WebhookSecret, payload))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewWebhookEvent(operation, target string, started time.Time, err error) WebhookEvent {
+	event := WebhookEvent{
+		Operation:  operation,
+		Target:     target,
+		Status:     "success",
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		event.Status = "failure"
+		event.Error = err.Error()
+	}
+	return event
+}
@@ -0,0 +1,66 @@
+package brew
+
+import (
+	"context"
+	"strings"
+)
+
+type InstallReason struct {
+	Package            string   `json:"package"`
+	InstalledOnRequest bool     `json:"installed_on_request"`
+	RequiredBy         []string `json:"required_by"`
+	Reason             string   `json:"reason"`
+}
+
+func (s *ServiceManager) Why(ctx context.Context, name string) (*InstallReason, error) {
+	pkg, err := s.Info(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkg.Installed) == 0 {
+		return nil, &NotInstalledError{Name: name}
+	}
+
+	dependents, err := s.cachedList(ctx, "uses", "--installed", "--recursive", name)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := s.cachedList(ctx, "leaves")
+	if err != nil {
+		return nil, err
+	}
+
+	isLeaf := make(map[string]bool, len(leaves))
+	for _, leaf := range leaves {
+		isLeaf[leaf] = true
+	}
+
+	requiredBy := make([]string, 0)
+	for _, dep := range dependents {
+		if isLeaf[dep] {
+			requiredBy = append(requiredBy, dep)
+		}
+	}
+
+	onRequest := pkg.Installed[0].InstalledOnRequest
+	return &InstallReason{
+		Package:            pkg.Name,
+		InstalledOnRequest: onRequest,
+		RequiredBy:         requiredBy,
+		Reason:             installReason(onRequest, requiredBy),
+	}, nil
+}
+
+func installReason(onRequest bool, requiredBy []string) string {
+	switch {
+	case onRequest && len(requiredBy) > 0:
+		return "installed on request; also a dependency of " + strings.Join(requiredBy, ", ")
+	case onRequest:
+		return "installed on request"
+	case len(requiredBy) > 0:
+		return "dependency of " + strings.Join(requiredBy, ", ")
+	default:
+		return "installed as a dependency, but no installed package requires it anymore"
+	}
+}
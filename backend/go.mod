@@ -1,3 +1,5 @@
 module brew-manager
 
 go 1.25.6
+
+require golang.org/x/net v0.58.0
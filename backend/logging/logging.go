@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var currentLevel atomic.Int32
+
+func init() {
+	currentLevel.Store(int32(LevelInfo))
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int32(l))
+	}
+}
+
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q; must be one of: debug, info, warn, error", s)
+	}
+}
+
+func SetLevel(l Level) {
+	currentLevel.Store(int32(l))
+}
+
+func CurrentLevel() Level {
+	return Level(currentLevel.Load())
+}
+
+func Enabled(l Level) bool {
+	return l >= CurrentLevel()
+}
+
+func Logf(l Level, format string, args ...interface{}) {
+	if !Enabled(l) {
+		return
+	}
+	Always(l, format, args...)
+}
+
+// Always logs at level l regardless of the configured threshold. It is for
+// lines that must never be filtered, such as failed requests.
+func Always(l Level, format string, args ...interface{}) {
+	log.Printf(l.String()+": "+format, args...)
+}
+
+func Debugf(format string, args ...interface{}) {
+	Logf(LevelDebug, format, args...)
+}
+
+func Infof(format string, args ...interface{}) {
+	Logf(LevelInfo, format, args...)
+}
+
+func Warnf(format string, args ...interface{}) {
+	Logf(LevelWarn, format, args...)
+}
+
+func Errorf(format string, args ...interface{}) {
+	Logf(LevelError, format, args...)
+}
+
+type Logger struct {
+	requestID string
+}
+
+type loggerKey struct{}
+
+func NewLogger(requestID string) *Logger {
+	return &Logger{requestID: requestID}
+}
+
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+func FromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return nil
+	}
+	l, _ := ctx.Value(loggerKey{}).(*Logger)
+	return l
+}
+
+func (l *Logger) Logf(level Level, format string, args ...interface{}) {
+	if !Enabled(level) {
+		return
+	}
+	l.Always(level, format, args...)
+}
+
+func (l *Logger) Always(level Level, format string, args ...interface{}) {
+	if l == nil || l.requestID == "" {
+		Always(level, format, args...)
+		return
+	}
+	Always(level, "[%s] "+format, append([]interface{}{l.requestID}, args...)...)
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Logf(LevelDebug, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Logf(LevelInfo, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Logf(LevelWarn, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Logf(LevelError, format, args...)
+}
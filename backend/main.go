@@ -3,65 +3,260 @@ package main
 import (
 	"brew-manager/api"
 	"brew-manager/brew"
+	"brew-manager/logging"
 	"context"
+	"fmt"
+	"golang.org/x/net/netutil"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 )
 
 const (
-	defaultPort        = "8080"
-	defaultCORSOrigins = "*"
-	shutdownTimeout    = 30 * time.Second
-	serverReadTimeout  = 30 * time.Second
-	serverWriteTimeout = 10 * time.Minute 
+	defaultPort            = "8080"
+	defaultCORSOrigins     = "*"
+	defaultCORSMethods     = "GET,POST,PUT,DELETE,OPTIONS"
+	defaultCORSHeaders     = "Content-Type,Authorization"
+	defaultShutdownTimeout = 30 * time.Second
+	defaultReadTimeout     = 30 * time.Second
+	defaultWriteTimeout    = 10 * time.Minute 
 
-	serverIdleTimeout  = 120 * time.Second
+	defaultIdleTimeout     = 120 * time.Second
+	brewCheckInterval      = 30 * time.Second
+
+	defaultMaxConcurrentPerIP = 2
+	defaultMaxStreams         = 16
 )
 
 func main() {
 
+	level, err := logging.ParseLevel(getEnv("LOG_LEVEL", "info"))
+	if err != nil {
+		log.Printf("WARN: %v; using info", err)
+	}
+	logging.SetLevel(level)
+
 	port := getEnv("PORT", defaultPort)
-	corsOrigins := parseOrigins(getEnv("CORS_ORIGINS", defaultCORSOrigins))
+	corsOrigins := filterValidOrigins(splitCSV(getEnv("CORS_ORIGINS", defaultCORSOrigins)))
+	corsMethods := splitCSV(strings.ToUpper(getEnv("CORS_ALLOWED_METHODS", defaultCORSMethods)))
+	corsHeaders := splitCSV(getEnv("CORS_ALLOWED_HEADERS", defaultCORSHeaders))
+	corsCredentials := getEnvBool("CORS_ALLOW_CREDENTIALS", false)
 
-	brewSvc := brew.NewService(brew.DefaultConfig())
+	brewCfg := brew.DefaultConfig()
+	brewCfg.DisableCheatSheet = getEnvBool("DISABLE_CHEATSHEET", false)
+	brewCfg.BrewPath = getEnv("BREW_PATH", brewCfg.BrewPath)
+	brewCfg.ProxyURL = os.Getenv("BREW_PROXY")
+	brewCfg.ServicePorts = parseServicePorts(os.Getenv("SERVICE_PORTS"))
+	brewCfg.MaxConcurrentReads = getEnvInt("MAX_CONCURRENT_READS", brewCfg.MaxConcurrentReads)
+	brewCfg.MaxOutputBytes = int64(getEnvInt("MAX_OUTPUT_BYTES", int(brewCfg.MaxOutputBytes)))
+	brewCfg.CheatSheetUserAgent = getEnv("CHEATSHEET_USER_AGENT", brewCfg.CheatSheetUserAgent)
+	brewCfg.CheatSheetAccept = os.Getenv("CHEATSHEET_ACCEPT")
+	brewCfg.WebhookURL = os.Getenv("WEBHOOK_URL")
+	brewCfg.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	brewCfg.Prefixes = parsePrefixes(os.Getenv("BREW_PREFIXES"))
+	if len(brewCfg.Prefixes) == 0 {
+		brewCfg.Prefixes = brew.DetectPrefixes()
+	}
+
+	brewSvc := brew.NewService(brewCfg)
 	handler := api.NewHandler(brewSvc)
 
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	brewGuard := api.NewBrewGuard(brewSvc, brewCheckInterval)
+	brewGuard.Start(bgCtx)
+
+	readOnly := getEnvBool("READONLY", false)
+	logRedact := getEnvBool("LOG_REDACT", false)
+	auditPath := os.Getenv("AUDIT_LOG_PATH")
+	pollInterval := getEnvDuration("OUTDATED_POLL_INTERVAL", 0)
+	cleanupInterval := getEnvDuration("CLEANUP_INTERVAL", 0)
+	sseKeepAlive := getEnvDuration("SSE_KEEPALIVE_INTERVAL", 15*time.Second)
+	maxStreams := getEnvInt("MAX_STREAMS", defaultMaxStreams)
+	shutdownTimeout := getEnvSeconds("SHUTDOWN_TIMEOUT", defaultShutdownTimeout)
+	maxConcurrentPerIP := getEnvInt("MAX_CONCURRENT_PER_IP", defaultMaxConcurrentPerIP)
+	maxHeaderBytes := getEnvInt("MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes)
+	maxConnections := getEnvInt("MAX_CONNECTIONS", 0)
+	shutdownToken := os.Getenv("SHUTDOWN_TOKEN")
+	readTimeout := getEnvDuration("SERVER_READ_TIMEOUT", defaultReadTimeout)
+	writeTimeout := getEnvDuration("SERVER_WRITE_TIMEOUT", defaultWriteTimeout)
+	idleTimeout := getEnvDuration("SERVER_IDLE_TIMEOUT", defaultIdleTimeout)
+
+	trustedProxyList := splitCSV(os.Getenv("TRUSTED_PROXIES"))
+	trustedProxies, err := api.ParseTrustedProxies(trustedProxyList)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	tlsCert := os.Getenv("TLS_CERT")
+	tlsKey := os.Getenv("TLS_KEY")
+	if (tlsCert == "") != (tlsKey == "") {
+		log.Fatalf("FATAL: TLS_CERT and TLS_KEY must both be set to enable TLS")
+	}
+	useTLS := tlsCert != ""
+
+	settings := api.ServerSettings{
+		Port:                 port,
+		CORSOrigins:          corsOrigins,
+		CORSMethods:          corsMethods,
+		CORSHeaders:          corsHeaders,
+		CORSAllowCredentials: corsCredentials,
+		TLS:                  useTLS,
+		ReadOnly:             readOnly,
+		AuditLog:             auditPath != "",
+		Webhook:              brewCfg.WebhookURL != "",
+		LogLevel:             strings.ToLower(level.String()),
+		LogRedact:            logRedact,
+		ShutdownTimeout:      shutdownTimeout.String(),
+		MaxConcurrentPerIP:   maxConcurrentPerIP,
+		MaxHeaderBytes:       maxHeaderBytes,
+		MaxConnections:       maxConnections,
+		RemoteShutdown:       shutdownToken != "",
+		ReadTimeout:          readTimeout.String(),
+		WriteTimeout:         writeTimeout.String(),
+		IdleTimeout:          idleTimeout.String(),
+		TrustedProxies:       trustedProxyList,
+		SSEKeepAlive:         sseKeepAlive.String(),
+		MaxStreams:           maxStreams,
+	}
+
+	if pollInterval > 0 {
+		settings.OutdatedPollInterval = pollInterval.String()
+		log.Printf("INFO: Polling for outdated packages every %v", pollInterval)
+		api.NewOutdatedPoller(brewSvc, handler.Events(), pollInterval).Start(bgCtx)
+	}
+
+	if cleanupInterval > 0 {
+		settings.CleanupInterval = cleanupInterval.String()
+		log.Printf("INFO: Running brew cleanup every %v", cleanupInterval)
+		api.NewCleanupScheduler(brewSvc, cleanupInterval).Start(bgCtx)
+	}
+
+	if writeTimeout > 0 {
+		log.Printf("INFO: Server write timeout is %v; streaming endpoints clear it and routes with longer timeouts extend it per request", writeTimeout)
+	} else {
+		log.Printf("INFO: Server write timeout disabled")
+	}
+
+	labelsPath := os.Getenv("LABELS_PATH")
+	if labelsPath == "" {
+		if labelsPath, err = api.DefaultLabelStorePath(); err != nil {
+			log.Printf("WARN: Could not determine config directory for labels: %v; labels are disabled", err)
+		}
+	}
+	if labelsPath != "" {
+		labelStore, err := api.NewLabelStore(labelsPath)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		handler.SetLabelStore(labelStore)
+		log.Printf("INFO: Package labels stored at %s", labelsPath)
+	}
+
+	requestStats := api.NewRequestStats()
+	handler.SetRequestStats(requestStats)
+	handler.SetRouteTimeouts(parseRouteTimeouts(os.Getenv("ROUTE_TIMEOUTS")))
+	handler.SetWriteTimeout(writeTimeout)
+	handler.SetSSEKeepAlive(sseKeepAlive)
+	handler.SetMaxStreams(maxStreams)
+	handler.SetServerSettings(settings)
+
 	mux := http.NewServeMux()
-	registerRoutes(mux, handler)
+	registerRoutes(mux, handler, shutdownToken)
 
 	corsConfig := api.CORSConfig{
-		AllowedOrigins: corsOrigins,
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Content-Type", "Authorization"},
-		MaxAge:         86400,
+		AllowedOrigins:   corsOrigins,
+		AllowedMethods:   corsMethods,
+		AllowedHeaders:   corsHeaders,
+		AllowCredentials: corsCredentials,
+		MaxAge:           86400,
+	}
+
+	var auditLogger *api.AuditLogger
+	if auditPath != "" {
+		var err error
+		auditLogger, err = api.NewAuditLogger(auditPath)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		log.Printf("INFO: Audit logging enabled: %s", auditPath)
 	}
+	defer auditLogger.Close()
 
-	wrappedHandler := api.ChainMiddleware(
-		mux,
+	if brewSvc.WebhookEnabled() {
+		log.Printf("INFO: Webhook notifications enabled")
+	}
+
+	middlewares := []func(http.Handler) http.Handler{
+		api.RequestIDMiddleware,
+		api.ClientIPMiddlewareFunc(trustedProxies),
 		api.CORSMiddlewareFunc(corsConfig),
-		api.LoggingMiddleware,
+		api.LoggingMiddlewareFunc(api.LoggingConfig{RedactQuery: logRedact}),
+		api.StatsMiddlewareFunc(requestStats),
+		api.AuditMiddlewareFunc(auditLogger),
+		api.WebhookMiddlewareFunc(brewSvc),
 		api.RecoveryMiddleware,
+	}
+	if readOnly {
+		log.Printf("INFO: Read-only mode enabled; mutating operations are disabled")
+		middlewares = append(middlewares, api.ReadOnlyMiddleware)
+	}
+	middlewares = append(middlewares,
+		api.ConcurrencyLimitMiddlewareFunc(api.NewConcurrencyLimiter(maxConcurrentPerIP)),
+		api.BrewGuardMiddlewareFunc(brewGuard),
+		api.BrewOptionsMiddleware,
+		api.PrettyJSONMiddleware,
+		handler.WriteDeadlineMiddleware,
 	)
 
+	wrappedHandler := api.ChainMiddleware(mux, middlewares...)
+
+	root := http.NewServeMux()
+	root.HandleFunc("/livez", handler.Livez)
+	root.HandleFunc("/readyz", handler.Readyz)
+	root.Handle("/", wrappedHandler)
+
 	server := &http.Server{
-		Addr:         ":" + port,
-		Handler:      wrappedHandler,
-		ReadTimeout:  serverReadTimeout,
-		WriteTimeout: serverWriteTimeout,
-		IdleTimeout:  serverIdleTimeout,
+		Addr:           ":" + port,
+		Handler:        root,
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
+		IdleTimeout:    idleTimeout,
+		MaxHeaderBytes: maxHeaderBytes,
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		log.Fatalf("FATAL: Failed to listen on %s: %v", server.Addr, err)
+	}
+	if maxConnections > 0 {
+		log.Printf("INFO: Limiting server to %d concurrent connections", maxConnections)
+		listener = netutil.LimitListener(listener, maxConnections)
 	}
 
 	serverErrors := make(chan error, 1)
 	go func() {
-		log.Printf("INFO: Starting backend server on http://localhost:%s", port)
 		log.Printf("INFO: CORS origins: %v", corsOrigins)
-		serverErrors <- server.ListenAndServe()
+		if corsCredentials {
+			log.Printf("INFO: CORS credentials allowed for matched origins")
+		}
+		if useTLS {
+			log.Printf("INFO: Starting backend server with TLS on https://localhost:%s", port)
+			serverErrors <- server.ServeTLS(listener, tlsCert, tlsKey)
+			return
+		}
+		log.Printf("INFO: Starting backend server without TLS on http://localhost:%s", port)
+		serverErrors <- server.Serve(listener)
 	}()
+	handler.SetReady(true)
 
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
@@ -73,30 +268,63 @@ func main() {
 		}
 	case sig := <-shutdown:
 		log.Printf("INFO: Shutdown signal received: %v", sig)
+		gracefulShutdown(server, handler, stopBackground, shutdownTimeout)
+	case <-handler.ShutdownRequested():
+		log.Printf("INFO: Shutdown requested via API")
+		gracefulShutdown(server, handler, stopBackground, shutdownTimeout)
+	}
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-		defer cancel()
+func gracefulShutdown(server *http.Server, handler *api.Handler, stopBackground context.CancelFunc, timeout time.Duration) {
+	handler.SetReady(false)
+	stopBackground()
 
-		if err := server.Shutdown(ctx); err != nil {
-			log.Printf("ERROR: Graceful shutdown failed: %v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-			server.Close()
-		}
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("ERROR: Graceful shutdown failed: %v", err)
 
-		log.Printf("INFO: Server shutdown complete")
+		server.Close()
 	}
+
+	log.Printf("INFO: Server shutdown complete")
 }
 
-func registerRoutes(mux *http.ServeMux, h *api.Handler) {
+func registerRoutes(mux *http.ServeMux, h *api.Handler, shutdownToken string) {
 
 	mux.HandleFunc("/api/packages", h.ListPackages)
+	mux.HandleFunc("/api/packages/light", h.ListPackagesLight)
+	mux.HandleFunc("/api/packages/labels", h.HandleLabels)
+	mux.HandleFunc("/api/formulae", h.ListFormulae)
+	mux.HandleFunc("/api/casks", h.ListCasks)
 	mux.HandleFunc("/api/packages/upgrade", h.UpgradePackage)
+	mux.HandleFunc("/api/packages/recent", h.RecentPackages)
+	mux.HandleFunc("/api/packages/upgrade-preview", h.UpgradePreview)
+	mux.HandleFunc("/api/packages/outdated/grouped", h.GetOutdatedGrouped)
 	mux.HandleFunc("/api/packages/uninstall", h.UninstallPackage)
+	mux.HandleFunc("/api/packages/uninstall-batch", h.UninstallBatch)
+	mux.HandleFunc("/api/packages/upgrade-selected", h.UpgradeSelected)
 	mux.HandleFunc("/api/packages/reinstall", h.ReinstallPackage)
 	mux.HandleFunc("/api/packages/pin", h.PinPackage)
+	mux.HandleFunc("/api/packages/pin-batch", h.PinBatch)
 	mux.HandleFunc("/api/packages/usage", h.GetPackageUsage)
 	mux.HandleFunc("/api/packages/search", h.SearchPackages)
 	mux.HandleFunc("/api/packages/install", h.InstallPackage)
+	mux.HandleFunc("/api/packages/install-stream", h.InstallPackageStream)
+	mux.HandleFunc("/api/packages/install-version", h.InstallVersion)
+	mux.HandleFunc("/api/packages/install-preview", h.GetInstallPreview)
+	mux.HandleFunc("/api/packages/check-conflicts", h.CheckConflicts)
+	mux.HandleFunc("/api/packages/deps", h.GetDependencies)
+	mux.HandleFunc("/api/packages/uses", h.GetDependents)
+	mux.HandleFunc("/api/packages/why", h.GetInstallReason)
+	mux.HandleFunc("/api/packages/removable", h.GetRemovable)
+	mux.HandleFunc("/api/packages/deps-size", h.GetDepsSize)
+	mux.HandleFunc("/api/packages/describe", h.DescribePackage)
+	mux.HandleFunc("/api/packages/popularity", h.GetPopularity)
+	mux.HandleFunc("/api/packages/man", h.GetManPage)
+	mux.HandleFunc("/api/packages/readme", h.GetReadme)
+	mux.HandleFunc("/api/packages/buildlog", h.GetBuildLog)
 
 	mux.HandleFunc("/api/packages/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/api/packages/")
@@ -129,15 +357,38 @@ func registerRoutes(mux *http.ServeMux, h *api.Handler) {
 		http.NotFound(w, r)
 	})
 
+	mux.HandleFunc("/api/error-codes", h.ListErrorCodes)
+	mux.HandleFunc("/api/stats", h.GetStats)
+	mux.HandleFunc("/api/operations", h.ListOperations)
+	mux.HandleFunc("/api/events", h.StreamEvents)
+
+	mux.HandleFunc("/api/catalog/formulae", h.ListCatalogFormulae)
+	mux.HandleFunc("/api/catalog/casks", h.ListCatalogCasks)
+
 	mux.HandleFunc("/api/services", h.ListServices)
 	mux.HandleFunc("/api/services/control", h.ControlService)
+	mux.HandleFunc("/api/services/detail", h.GetServiceDetail)
+	mux.HandleFunc("/api/services/health", h.GetServiceHealth)
+	mux.HandleFunc("/api/services/restart-all", h.RestartAllServices)
+	mux.HandleFunc("/api/services/stop-all", h.StopAllServices)
 
 	mux.HandleFunc("/api/update", h.HandleSystemUpdate)
 	mux.HandleFunc("/api/cleanup", h.HandleSystemCleanup)
 	mux.HandleFunc("/api/doctor", h.HandleDoctor)
+	mux.HandleFunc("/api/doctor/fix", h.HandleDoctorFix)
+	mux.HandleFunc("/api/brew/exec", h.ExecBrewCommand)
 
 	mux.HandleFunc("/api/system/update", h.HandleSystemUpdate)
+	mux.HandleFunc("/api/system/update-check", h.HandleUpdateCheck)
 	mux.HandleFunc("/api/system/cleanup", h.HandleSystemCleanup)
+	mux.HandleFunc("/api/system/cache-size", h.GetCacheSize)
+	mux.HandleFunc("/api/system/diagnostics", h.GetDiagnostics)
+	mux.HandleFunc("/api/export", h.Export)
+	mux.HandleFunc("/api/import", h.Import)
+	mux.HandleFunc("/api/system/analytics", h.HandleAnalytics)
+	mux.HandleFunc("/api/system/prefixes", h.ListPrefixes)
+	mux.HandleFunc("/api/system/config", h.GetConfig)
+	mux.HandleFunc("/api/system/shutdown", api.TokenAuth(h.HandleShutdown, shutdownToken))
 }
 
 func getEnv(key, defaultValue string) string {
@@ -147,19 +398,154 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func parseOrigins(s string) []string {
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("WARN: Invalid boolean for %s=%q, using default %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("WARN: Invalid duration for %s=%q, using default %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("WARN: Invalid integer for %s=%q, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvSeconds(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		log.Printf("WARN: Invalid seconds for %s=%q, using default %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func splitCSV(s string) []string {
 	if s == "" {
 		return []string{}
 	}
 
 	parts := strings.Split(s, ",")
-	origins := make([]string, 0, len(parts))
+	values := make([]string, 0, len(parts))
 	for _, p := range parts {
 		trimmed := strings.TrimSpace(p)
 		if trimmed != "" {
-			origins = append(origins, trimmed)
+			values = append(values, trimmed)
 		}
 	}
-	return origins
+	return values
 }
 
+func filterValidOrigins(origins []string) []string {
+	valid := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		if err := validateOrigin(origin); err != nil {
+			log.Printf("WARN: Ignoring invalid CORS origin %q: %v", origin, err)
+			continue
+		}
+		valid = append(valid, origin)
+	}
+	return valid
+}
+
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("origin must start with http:// or https://")
+	}
+	if u.Host == "" || u.User != nil {
+		return fmt.Errorf("origin must be of the form scheme://host[:port]")
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("origin must not include a path, query, or fragment")
+	}
+	if strings.Contains(strings.TrimPrefix(u.Hostname(), "*."), "*") {
+		return fmt.Errorf("wildcards are only supported as a leading *. subdomain")
+	}
+	return nil
+}
+
+func parsePrefixes(s string) map[string]string {
+	prefixes := make(map[string]string)
+	for _, entry := range splitCSV(s) {
+		name, path, ok := strings.Cut(entry, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || path == "" {
+			log.Printf("WARN: Ignoring invalid BREW_PREFIXES entry %q; expected name=/path/to/brew", entry)
+			continue
+		}
+		prefixes[name] = path
+	}
+	return prefixes
+}
+
+func parseRouteTimeouts(s string) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range splitCSV(s) {
+		route, value, ok := strings.Cut(entry, "=")
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		route = strings.Trim(strings.TrimSpace(route), "/")
+		route = strings.TrimPrefix(route, "api/")
+		if !ok || route == "" || err != nil || timeout <= 0 {
+			log.Printf("WARN: Ignoring invalid ROUTE_TIMEOUTS entry %q; expected route=duration", entry)
+			continue
+		}
+		timeouts[route] = timeout
+	}
+	return timeouts
+}
+
+func parseServicePorts(s string) map[string]int {
+	ports := make(map[string]int)
+	for _, entry := range splitCSV(s) {
+		name, value, ok := strings.Cut(entry, "=")
+		port, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || strings.TrimSpace(name) == "" || err != nil || port <= 0 || port > 65535 {
+			log.Printf("WARN: Ignoring invalid SERVICE_PORTS entry %q; expected name=port", entry)
+			continue
+		}
+		ports[strings.TrimSpace(name)] = port
+	}
+	return ports
+}
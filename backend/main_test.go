@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestValidateOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		valid  bool
+	}{
+		{"*", true},
+		{"http://localhost:3000", true},
+		{"https://brew.example.com", true},
+		{"https://*.example.com", true},
+		{"localhost:3000", false},
+		{"example.com", false},
+		{"ftp://example.com", false},
+		{"https://example.com/", false},
+		{"https://example.com/app", false},
+		{"https://example.com?x=1", false},
+		{"https://user@example.com", false},
+		{"https://exa*mple.com", false},
+		{"https://*.*.example.com", false},
+		{"https://", false},
+	}
+
+	for _, tt := range tests {
+		err := validateOrigin(tt.origin)
+		if tt.valid && err != nil {
+			t.Errorf("validateOrigin(%q) = %v, want nil", tt.origin, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("validateOrigin(%q) = nil, want error", tt.origin)
+		}
+	}
+}